@@ -7,6 +7,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/subnet-evm/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 )
 
@@ -45,6 +46,16 @@ func PackTeleporterMessage(message TeleporterMessage) ([]byte, error) {
 	return args.Pack(message)
 }
 
+// HashTeleporterMessage computes the hash that TeleporterMessenger stores for a message, both as the
+// message hash on the source chain and as the failed message hash on the destination chain.
+func HashTeleporterMessage(message TeleporterMessage) (common.Hash, error) {
+	messageBytes, err := PackTeleporterMessage(message)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(messageBytes), nil
+}
+
 func UnpackTeleporterMessage(messageBytes []byte) (*TeleporterMessage, error) {
 	args := abi.Arguments{
 		{
@@ -85,6 +96,56 @@ func PackRetryMessageExecution(originChainID ids.ID, message TeleporterMessage)
 	return abi.Pack("retryMessageExecution", originChainID, message)
 }
 
+// PackRetrySendCrossChainMessage packs a sent message to form a call to the retrySendCrossChainMessage function
+func PackRetrySendCrossChainMessage(destinationBlockchainID ids.ID, message TeleporterMessage) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+
+	return abi.Pack("retrySendCrossChainMessage", destinationBlockchainID, message)
+}
+
+// PackAddFeeAmount packs an additional fee for a sent message to form a call to the addFeeAmount function
+func PackAddFeeAmount(
+	destinationBlockchainID ids.ID,
+	messageID *big.Int,
+	feeTokenAddress common.Address,
+	additionalFeeAmount *big.Int,
+) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+
+	return abi.Pack("addFeeAmount", destinationBlockchainID, messageID, feeTokenAddress, additionalFeeAmount)
+}
+
+// PackRedeemRelayerRewards packs a fee token address to form a call to the redeemRelayerRewards function
+func PackRedeemRelayerRewards(feeTokenAddress common.Address) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+
+	return abi.Pack("redeemRelayerRewards", feeTokenAddress)
+}
+
+// PackSendSpecifiedReceipts packs received message IDs to form a call to the sendSpecifiedReceipts function
+func PackSendSpecifiedReceipts(
+	originBlockchainID ids.ID,
+	messageIDs []*big.Int,
+	feeInfo TeleporterFeeInfo,
+	allowedRelayerAddresses []common.Address,
+) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+
+	return abi.Pack("sendSpecifiedReceipts", originBlockchainID, messageIDs, feeInfo, allowedRelayerAddresses)
+}
+
 // PackReceiveCrossChainMessage packs a ReceiveCrossChainMessageInput to form a call to the receiveCrossChainMessage function
 func PackReceiveCrossChainMessage(messageIndex uint32, relayerRewardAddress common.Address) ([]byte, error) {
 	abi, err := TeleporterMessengerMetaData.GetAbi()
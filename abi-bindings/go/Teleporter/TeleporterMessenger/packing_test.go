@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -63,6 +64,128 @@ func TestPackUnpackTeleporterMessage(t *testing.T) {
 	require.True(t, bytes.Equal(message.Message, unpacked.Message))
 }
 
+func TestHashTeleporterMessage(t *testing.T) {
+	message := createTestTeleporterMessage(4)
+	b, err := PackTeleporterMessage(message)
+	require.NoError(t, err)
+
+	hash, err := HashTeleporterMessage(message)
+	require.NoError(t, err)
+	require.Equal(t, crypto.Keccak256Hash(b), hash)
+
+	// Altering any field of the message changes its hash
+	alterations := map[string]func(m *TeleporterMessage){
+		"messageID":               func(m *TeleporterMessage) { m.MessageID = big.NewInt(5) },
+		"requiredGasLimit":        func(m *TeleporterMessage) { m.RequiredGasLimit = big.NewInt(3) },
+		"allowedRelayerAddresses": func(m *TeleporterMessage) { m.AllowedRelayerAddresses = []common.Address{} },
+		"receipts":                func(m *TeleporterMessage) { m.Receipts[0].ReceivedMessageID = big.NewInt(2) },
+		"message":                 func(m *TeleporterMessage) { m.Message = []byte{1, 2, 3} },
+	}
+	for name, alter := range alterations {
+		t.Run(name, func(t *testing.T) {
+			altered := createTestTeleporterMessage(4)
+			alter(&altered)
+			alteredHash, err := HashTeleporterMessage(altered)
+			require.NoError(t, err)
+			require.NotEqual(t, hash, alteredHash)
+		})
+	}
+}
+
+func TestPackRetrySendCrossChainMessage(t *testing.T) {
+	destinationBlockchainID := [32]byte{1, 2, 3, 4}
+	message := createTestTeleporterMessage(5)
+
+	b, err := PackRetrySendCrossChainMessage(destinationBlockchainID, message)
+	require.NoError(t, err)
+
+	teleporterABI, err := TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	method, err := teleporterABI.MethodById(b[:4])
+	require.NoError(t, err)
+	require.Equal(t, "retrySendCrossChainMessage", method.Name)
+
+	args, err := method.Inputs.Unpack(b[4:])
+	require.NoError(t, err)
+	var unpacked struct {
+		DestinationBlockchainID [32]byte
+		Message                 TeleporterMessage
+	}
+	require.NoError(t, method.Inputs.Copy(&unpacked, args))
+	require.Equal(t, destinationBlockchainID, unpacked.DestinationBlockchainID)
+	require.Equal(t, message, unpacked.Message)
+}
+
+func TestPackAddFeeAmount(t *testing.T) {
+	destinationBlockchainID := [32]byte{1, 2, 3, 4}
+	messageID := big.NewInt(5)
+	feeTokenAddress := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	amount := big.NewInt(100)
+
+	b, err := PackAddFeeAmount(destinationBlockchainID, messageID, feeTokenAddress, amount)
+	require.NoError(t, err)
+
+	teleporterABI, err := TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	method, err := teleporterABI.MethodById(b[:4])
+	require.NoError(t, err)
+	require.Equal(t, "addFeeAmount", method.Name)
+
+	args, err := method.Inputs.Unpack(b[4:])
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{destinationBlockchainID, messageID, feeTokenAddress, amount}, args)
+}
+
+func TestPackRedeemRelayerRewards(t *testing.T) {
+	feeTokenAddress := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+
+	b, err := PackRedeemRelayerRewards(feeTokenAddress)
+	require.NoError(t, err)
+
+	teleporterABI, err := TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	method, err := teleporterABI.MethodById(b[:4])
+	require.NoError(t, err)
+	require.Equal(t, "redeemRelayerRewards", method.Name)
+
+	args, err := method.Inputs.Unpack(b[4:])
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{feeTokenAddress}, args)
+}
+
+func TestPackSendSpecifiedReceipts(t *testing.T) {
+	originBlockchainID := [32]byte{1, 2, 3, 4}
+	messageIDs := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	feeInfo := TeleporterFeeInfo{
+		FeeTokenAddress: common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+		Amount:          big.NewInt(1),
+	}
+	allowedRelayerAddresses := []common.Address{common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")}
+
+	b, err := PackSendSpecifiedReceipts(originBlockchainID, messageIDs, feeInfo, allowedRelayerAddresses)
+	require.NoError(t, err)
+
+	teleporterABI, err := TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	method, err := teleporterABI.MethodById(b[:4])
+	require.NoError(t, err)
+	require.Equal(t, "sendSpecifiedReceipts", method.Name)
+
+	args, err := method.Inputs.Unpack(b[4:])
+	require.NoError(t, err)
+	var unpacked struct {
+		OriginBlockchainID      [32]byte
+		MessageIDs              []*big.Int
+		FeeInfo                 TeleporterFeeInfo
+		AllowedRelayerAddresses []common.Address
+	}
+	require.NoError(t, method.Inputs.Copy(&unpacked, args))
+	require.Equal(t, originBlockchainID, unpacked.OriginBlockchainID)
+	require.Equal(t, messageIDs, unpacked.MessageIDs)
+	require.Equal(t, feeInfo, unpacked.FeeInfo)
+	require.Equal(t, allowedRelayerAddresses, unpacked.AllowedRelayerAddresses)
+}
+
 func TestUnpackEvent(t *testing.T) {
 	mockBlockchainID := [32]byte{1, 2, 3, 4}
 	messageID := big.NewInt(1)
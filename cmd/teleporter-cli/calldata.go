@@ -0,0 +1,172 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/predicate"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ava-labs/subnet-evm/x/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	calldataRawTx bool
+
+	errShortCalldata = errors.New("input is too short to hold a method selector")
+)
+
+// calldataOutput is a decoded call of a TeleporterMessenger method. TxHash and To are only set
+// when the input is taken from a transaction.
+type calldataOutput struct {
+	TxHash      string                   `json:"txHash,omitempty"`
+	To          string                   `json:"to,omitempty"`
+	Method      string                   `json:"method"`
+	Selector    string                   `json:"selector"`
+	Arguments   interface{}              `json:"arguments"`
+	WarpMessage *signedWarpMessageOutput `json:"warpMessage,omitempty"`
+	Warnings    []string                 `json:"warnings,omitempty"`
+}
+
+var calldataCmd = &cobra.Command{
+	Use:   "calldata ([--rpc RPC_URL] TRANSACTION_HASH | INPUT_HEX | --raw-tx SIGNED_TX_HEX)",
+	Short: "Decodes the input of a call to the TeleporterMessenger contract",
+	Long: `Decodes the input of a transaction calling the TeleporterMessenger contract
+into the method called and its arguments. With --rpc the argument is the hash
+of the transaction to fetch. Otherwise the argument is the hex encoded input
+data, or with --raw-tx the hex encoded signed transaction.
+
+For receiveCrossChainMessage, the signed Warp message is read from the Warp
+predicate at the messageIndex argument in the access list of the transaction,
+and decoded down to the Teleporter message it carries. The predicate is only
+available when the argument is a transaction rather than input data.`,
+	Args: cobra.ExactArgs(1),
+	Run:  calldataRun,
+}
+
+func calldataRun(cmd *cobra.Command, args []string) {
+	var tx *types.Transaction
+	switch {
+	case calldataRawTx:
+		b, err := hexutil.Decode(ensureHexPrefix(args[0]))
+		cobra.CheckErr(err)
+		tx = new(types.Transaction)
+		cobra.CheckErr(tx.UnmarshalBinary(b))
+	case cmd.Flags().Changed("rpc"):
+		txHashes, err := parseTransactionHashes(args)
+		cobra.CheckErr(err)
+		tx, _, err = client.TransactionByHash(context.Background(), txHashes[0])
+		cobra.CheckErr(err)
+	}
+
+	var out calldataOutput
+	if tx != nil {
+		var err error
+		out, err = decodeCalldata(tx.Data(), tx.AccessList())
+		cobra.CheckErr(err)
+		out.TxHash = tx.Hash().Hex()
+		if tx.To() != nil {
+			out.To = tx.To().Hex()
+		}
+	} else {
+		data, err := hexutil.Decode(ensureHexPrefix(args[0]))
+		cobra.CheckErr(err)
+		out, err = decodeCalldata(data, nil)
+		cobra.CheckErr(err)
+	}
+
+	writeOutput(cmd, out)
+	cmd.Println("Calldata command ran successfully for", out.Method)
+}
+
+// decodeCalldata decodes the input data of a call to a TeleporterMessenger method. The signed Warp
+// message of a receiveCrossChainMessage call is decoded from the Warp predicates of accessList.
+// Problems with the predicate are reported as warnings, since they are what the call failed on.
+func decodeCalldata(data []byte, accessList types.AccessList) (calldataOutput, error) {
+	if len(data) < 4 {
+		return calldataOutput{}, errShortCalldata
+	}
+	method, err := teleporterABI.MethodById(data[:4])
+	if err != nil {
+		return calldataOutput{}, fmt.Errorf("unknown TeleporterMessenger method selector %s", hexutil.Encode(data[:4]))
+	}
+	arguments, err := argumentsDecoder{args: method.Inputs}.decode(data[4:])
+	if err != nil {
+		return calldataOutput{}, fmt.Errorf("failed to decode %s arguments: %w", method.Name, err)
+	}
+	out := calldataOutput{
+		Method:    method.Name,
+		Selector:  hexutil.Encode(data[:4]),
+		Arguments: arguments,
+	}
+	if method.Name != "receiveCrossChainMessage" {
+		return out, nil
+	}
+
+	// The arguments decoded above, so they unpack into messageIndex and relayerRewardAddress.
+	values, _ := method.Inputs.Unpack(data[4:])
+	messageIndex := values[0].(uint32)
+	predicates := getWarpPredicates(accessList)
+	if int(messageIndex) >= len(predicates) {
+		out.Warnings = append(out.Warnings, fmt.Sprintf(
+			"no Warp predicate at message index %d, the access list has %d", messageIndex, len(predicates)))
+		return out, nil
+	}
+	warpMessage, err := parseWarpPredicate(predicates[messageIndex])
+	if err != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf(
+			"failed to decode Warp predicate at message index %d: %s", messageIndex, err))
+		return out, nil
+	}
+	out.WarpMessage = &warpMessage
+	return out, nil
+}
+
+// getWarpPredicates returns the storage slots of the access list tuples of the Warp precompile,
+// in the order the precompile indexes them by
+func getWarpPredicates(accessList types.AccessList) [][]common.Hash {
+	var predicates [][]common.Hash
+	for _, tuple := range accessList {
+		if tuple.Address == warp.ContractAddress {
+			predicates = append(predicates, tuple.StorageKeys)
+		}
+	}
+	return predicates
+}
+
+// parseWarpPredicate decodes the signed Warp message packed into predicate storage slots
+func parseWarpPredicate(storageKeys []common.Hash) (signedWarpMessageOutput, error) {
+	predicateBytes, err := predicate.UnpackPredicate(utils.HashSliceToBytes(storageKeys))
+	if err != nil {
+		return signedWarpMessageOutput{}, err
+	}
+	signedMsg, err := avalancheWarp.ParseMessage(predicateBytes)
+	if err != nil {
+		return signedWarpMessageOutput{}, err
+	}
+	return newSignedWarpMessageOutput(signedMsg)
+}
+
+func init() {
+	rootCmd.AddCommand(calldataCmd)
+	calldataCmd.Flags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint to fetch the transaction from")
+	addChainFlag(calldataCmd)
+	calldataCmd.Flags().BoolVar(&calldataRawTx, "raw-tx", false,
+		"Decode the argument as a hex encoded signed transaction rather than input data")
+	calldataCmd.MarkFlagsMutuallyExclusive("rpc", "raw-tx")
+	calldataCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Input data and raw transactions are decoded without connecting to a node.
+		if calldataRawTx || (!cmd.Flags().Changed("rpc") && chainName == "") {
+			return callPersistentPreRunE(cmd, args)
+		}
+		return rpcPreRunE(cmd, args, nil)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/predicate"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ava-labs/subnet-evm/x/warp"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalldataCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"calldata"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "rpc and raw tx",
+			args: []string{"calldata", "--rpc", "http://127.0.0.1:9650", "--raw-tx", "0x01"},
+			err:  fmt.Errorf("[raw-tx rpc] were all set"),
+		},
+		{
+			name: "help",
+			args: []string{"calldata", "--help"},
+			err:  nil,
+			out:  "Decodes the input of a transaction calling the TeleporterMessenger contract",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+	calldataRawTx = false
+}
+
+func TestDecodeCalldata(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		SenderAddress:           common.HexToAddress("0x01"),
+		DestinationBlockchainID: ids.ID{2},
+		DestinationAddress:      common.HexToAddress("0x02"),
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{1, 2, 3},
+	}
+	messageBytes, err := teleportermessenger.PackTeleporterMessage(message)
+	require.NoError(t, err)
+	addressedCall, err := warpPayload.NewAddressedCall(common.HexToAddress("0x03").Bytes(), messageBytes)
+	require.NoError(t, err)
+	unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{1}, addressedCall.Bytes())
+	require.NoError(t, err)
+	signedMsg, err := avalancheWarp.NewMessage(unsignedMsg, &avalancheWarp.BitSetSignature{Signers: []byte{1}})
+	require.NoError(t, err)
+
+	warpTuple := types.AccessTuple{
+		Address:     warp.ContractAddress,
+		StorageKeys: utils.BytesToHashSlice(predicate.PackPredicate(signedMsg.Bytes())),
+	}
+	otherTuple := types.AccessTuple{
+		Address:     common.HexToAddress("0x04"),
+		StorageKeys: []common.Hash{{1}},
+	}
+	invalidTuple := types.AccessTuple{
+		Address:     warp.ContractAddress,
+		StorageKeys: []common.Hash{{1}},
+	}
+
+	pack := func(data []byte, err error) []byte {
+		require.NoError(t, err)
+		return data
+	}
+	receiveAt := func(index uint32) []byte {
+		return pack(teleportermessenger.PackReceiveCrossChainMessage(index, common.HexToAddress("0x05")))
+	}
+
+	var tests = []struct {
+		name        string
+		data        []byte
+		accessList  types.AccessList
+		method      string
+		warpMessage bool
+		warnings    int
+		err         bool
+	}{
+		{
+			name:        "receive",
+			data:        receiveAt(0),
+			accessList:  types.AccessList{otherTuple, warpTuple},
+			method:      "receiveCrossChainMessage",
+			warpMessage: true,
+		},
+		{
+			name:        "receive second predicate",
+			data:        receiveAt(1),
+			accessList:  types.AccessList{invalidTuple, otherTuple, warpTuple},
+			method:      "receiveCrossChainMessage",
+			warpMessage: true,
+		},
+		{
+			name:       "receive invalid predicate",
+			data:       receiveAt(0),
+			accessList: types.AccessList{invalidTuple, warpTuple},
+			method:     "receiveCrossChainMessage",
+			warnings:   1,
+		},
+		{
+			name:       "receive missing predicate",
+			data:       receiveAt(1),
+			accessList: types.AccessList{warpTuple},
+			method:     "receiveCrossChainMessage",
+			warnings:   1,
+		},
+		{
+			name:     "receive input data",
+			data:     receiveAt(0),
+			method:   "receiveCrossChainMessage",
+			warnings: 1,
+		},
+		{
+			name:   "retry",
+			data:   pack(teleportermessenger.PackRetryMessageExecution(ids.ID{1}, message)),
+			method: "retryMessageExecution",
+		},
+		{
+			name: "unknown selector",
+			data: []byte{1, 2, 3, 4},
+			err:  true,
+		},
+		{
+			name: "short input",
+			data: []byte{1, 2, 3},
+			err:  true,
+		},
+		{
+			name: "invalid arguments",
+			data: receiveAt(0)[:20],
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := decodeCalldata(tt.data, tt.accessList)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.method, out.Method)
+			require.Len(t, out.Warnings, tt.warnings)
+			if !tt.warpMessage {
+				require.Nil(t, out.WarpMessage)
+				return
+			}
+			require.NotNil(t, out.WarpMessage)
+			require.NotNil(t, out.WarpMessage.Message)
+			require.Equal(t, "1", out.WarpMessage.Message.MessageID)
+		})
+	}
+}
@@ -0,0 +1,291 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/x/warp"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+const defaultLogChunkSize = 2048
+
+var errNoEventSignature = errors.New("log has no topics")
+
+// logFilterer is the subset of ethclient.Client used to page through logs
+type logFilterer interface {
+	FilterLogs(ctx context.Context, query interfaces.FilterQuery) ([]types.Log, error)
+}
+
+// latestLogReader is the subset of ethclient.Client used to filter logs up to the latest block
+type latestLogReader interface {
+	logFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// headerReader is the subset of ethclient.Client used to look up blocks by time
+type headerReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// getBlockchainID queries the Warp precompile for the Avalanche blockchain ID of the chain
+// the client is connected to. The TeleporterMessenger contract only caches its blockchain ID
+// after the first received message, so the precompile is the reliable source.
+func getBlockchainID(ctx context.Context, client ethclient.Client) (ids.ID, error) {
+	data, err := warp.PackGetBlockchainID()
+	if err != nil {
+		return ids.Empty, err
+	}
+	result, err := client.CallContract(ctx, interfaces.CallMsg{
+		To:   &warp.ContractAddress,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to get blockchain ID from the Warp precompile: %w", err)
+	}
+	return ids.ToID(result)
+}
+
+// parseBlockchainID parses a blockchain ID encoded either in cb58 or as 0x prefixed hex
+func parseBlockchainID(blockchainIDStr string) (ids.ID, error) {
+	if strings.HasPrefix(blockchainIDStr, "0x") {
+		b, err := hexutil.Decode(blockchainIDStr)
+		if err != nil {
+			return ids.Empty, fmt.Errorf("invalid blockchain ID %s: %w", blockchainIDStr, err)
+		}
+		return ids.ToID(b)
+	}
+	return ids.FromString(blockchainIDStr)
+}
+
+// getLookbackStartBlock returns the block height {lookback} blocks before the current height,
+// or zero if the chain is shorter than that.
+func getLookbackStartBlock(ctx context.Context, client ethclient.Client, lookback uint64) (uint64, error) {
+	currentBlockHeight, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if currentBlockHeight > lookback {
+		return currentBlockHeight - lookback, nil
+	}
+	return 0, nil
+}
+
+// getEventFromLogs returns the first log in logs that is successfully parsed by parser
+func getEventFromLogs[T any](logs []*types.Log, parser func(log types.Log) (T, error)) (T, error) {
+	for _, log := range logs {
+		if log.Address != teleporterAddress {
+			continue
+		}
+		event, err := parser(*log)
+		if err == nil {
+			return event, nil
+		}
+	}
+	return *new(T), fmt.Errorf("failed to find %T event in receipt logs", *new(T))
+}
+
+// getSendEventToDestination returns the SendCrossChainMessage event in logs of the message sent to
+// destinationBlockchainID. A transaction may send messages to several chains.
+func getSendEventToDestination(
+	logs []*types.Log,
+	messenger *teleportermessenger.TeleporterMessenger,
+	destinationBlockchainID ids.ID,
+) (*teleportermessenger.TeleporterMessengerSendCrossChainMessage, error) {
+	for _, log := range logs {
+		if log.Address != teleporterAddress {
+			continue
+		}
+		event, err := messenger.ParseSendCrossChainMessage(*log)
+		if err == nil && ids.ID(event.DestinationBlockchainID) == destinationBlockchainID {
+			return event, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to find a message sent to %s in receipt logs", destinationBlockchainID)
+}
+
+// isTeleporterWarpLog returns whether log is a SendWarpMessage log of a message sent by the Teleporter contract
+func isTeleporterWarpLog(log *types.Log) bool {
+	// The SendWarpMessage event indexes the sender address as its first topic.
+	return log.Address == warp.ContractAddress &&
+		len(log.Topics) > 1 &&
+		common.BytesToAddress(log.Topics[1].Bytes()) == teleporterAddress
+}
+
+// parseTeleporterWarpLog parses a SendWarpMessage log emitted by the Warp precompile into the
+// unsigned Warp message and the Teleporter message it carries.
+func parseTeleporterWarpLog(
+	log *types.Log,
+) (*avalancheWarp.UnsignedMessage, *teleportermessenger.TeleporterMessage, error) {
+	unsignedMsg, err := warp.UnpackSendWarpEventDataToMessage(log.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	addressedCall, err := warpPayload.ParseAddressedCall(unsignedMsg.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	teleporterMessage, err := teleportermessenger.UnpackTeleporterMessage(addressedCall.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return unsignedMsg, teleporterMessage, nil
+}
+
+// parseTeleporterLog parses the topics and data of a log emitted by the Teleporter contract
+// into the corresponding Teleporter event. Returns the event name and the parsed event.
+func parseTeleporterLog(topics []common.Hash, data []byte) (string, interface{}, error) {
+	if len(topics) == 0 {
+		return "", nil, errNoEventSignature
+	}
+	event, err := teleporterABI.EventByID(topics[0])
+	if err != nil {
+		return "", nil, err
+	}
+	out, err := teleportermessenger.FilterTeleporterEvents(topics, data, event.Name)
+	if err != nil {
+		return "", nil, err
+	}
+	return event.Name, out, nil
+}
+
+// filterLogsInChunks calls handler on every log matching query between fromBlock and toBlock,
+// inclusive, in block order. The range is queried in chunks of at most chunkSize blocks to
+// respect the log range limits of RPC nodes. A chunk that fails is retried with half the size,
+// in case the node also limits the number of logs per request.
+func filterLogsInChunks(
+	ctx context.Context,
+	client logFilterer,
+	query interfaces.FilterQuery,
+	fromBlock uint64,
+	toBlock uint64,
+	chunkSize uint64,
+	handler func(log types.Log) error,
+) error {
+	if chunkSize == 0 {
+		chunkSize = defaultLogChunkSize
+	}
+	for start := fromBlock; start <= toBlock; {
+		end := start + chunkSize - 1
+		if end > toBlock || end < start {
+			end = toBlock
+		}
+		query.FromBlock = new(big.Int).SetUint64(start)
+		query.ToBlock = new(big.Int).SetUint64(end)
+		logs, err := client.FilterLogs(ctx, query)
+		if err != nil {
+			if chunkSize == 1 || ctx.Err() != nil {
+				return fmt.Errorf("failed to filter logs in blocks %d to %d: %w", start, end, err)
+			}
+			chunkSize /= 2
+			continue
+		}
+		for _, log := range logs {
+			if err := handler(log); err != nil {
+				return err
+			}
+		}
+		if end == toBlock {
+			break
+		}
+		start = end + 1
+	}
+	return nil
+}
+
+// filterRecentLogs calls handler on every log matching query in the most recent lookback blocks,
+// in block order
+func filterRecentLogs(
+	ctx context.Context,
+	client latestLogReader,
+	query interfaces.FilterQuery,
+	lookback uint64,
+	handler func(log types.Log) error,
+) error {
+	latest, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	var fromBlock uint64
+	if latest > lookback {
+		fromBlock = latest - lookback
+	}
+	return filterLogsInChunks(ctx, client, query, fromBlock, latest, defaultLogChunkSize, handler)
+}
+
+// getBlockRange returns the block range given by the --from-block, --to-block and --since flags of
+// cmd. The range ends at the latest block unless --to-block is set, and --since takes precedence
+// over --from-block.
+func getBlockRange(
+	ctx context.Context,
+	cmd *cobra.Command,
+	client headerReader,
+	fromBlock uint64,
+	toBlock uint64,
+	since time.Duration,
+) (uint64, uint64, error) {
+	var err error
+	if !cmd.Flags().Changed("to-block") {
+		toBlock, err = client.BlockNumber(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if cmd.Flags().Changed("since") {
+		fromBlock, err = getBlockAtTime(ctx, client, time.Now().Add(-since))
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if fromBlock > toBlock {
+		return 0, 0, fmt.Errorf("from block %d is after to block %d", fromBlock, toBlock)
+	}
+	return fromBlock, toBlock, nil
+}
+
+// getBlockAtTime returns the number of the first block with a timestamp at or after t,
+// or the latest block if there is none.
+func getBlockAtTime(ctx context.Context, client headerReader, t time.Time) (uint64, error) {
+	latest, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	target := uint64(t.Unix())
+	var searchErr error
+	height := sort.Search(int(latest)+1, func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(uint64(i)))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return header.Time >= target
+	})
+	if searchErr != nil {
+		return 0, searchErr
+	}
+	if uint64(height) > latest {
+		return latest, nil
+	}
+	return uint64(height), nil
+}
@@ -0,0 +1,218 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/spf13/cobra"
+)
+
+const defaultConfigFileName = ".teleporter-cli.yaml"
+
+var (
+	configFile    string
+	chainName     string
+	chainNames    []string
+	fromChainName string
+	toChainName   string
+
+	// config is loaded on first use, so that commands that do not use chain profiles
+	// do not fail on a missing or invalid config file
+	config *cliConfig
+)
+
+// chainConfig is a named chain profile. Its fields mirror the subnet info used by the
+// e2e tests and the relayer config.
+type chainConfig struct {
+	RPCURL                    string `json:"rpc-url"`
+	WSURL                     string `json:"ws-url"`
+	BlockchainID              string `json:"blockchain-id"`
+	SubnetID                  string `json:"subnet-id"`
+	TeleporterAddress         string `json:"teleporter-address"`
+	TeleporterRegistryAddress string `json:"teleporter-registry-address"`
+	NodeURI                   string `json:"node-uri"`
+}
+
+// cliConfig is the contents of the config file, in JSON or YAML
+type cliConfig struct {
+	Chains          map[string]chainConfig         `json:"chains"`
+	PayloadDecoders map[string]payloadDecoderInput `json:"payload-decoders"`
+}
+
+// loadConfig reads the config file. A missing config file is only an error if it was
+// explicitly set with --config.
+func loadConfig() (*cliConfig, error) {
+	if config != nil {
+		return config, nil
+	}
+	path := configFile
+	explicit := path != ""
+	if !explicit {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, defaultConfigFileName)
+	}
+
+	loaded := &cliConfig{}
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+	case err != nil:
+		return nil, err
+	default:
+		if err := unmarshalInput(b, loaded); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+	config = loaded
+	return config, nil
+}
+
+// getChainConfig returns the profile of the named chain
+func getChainConfig(name string) (chainConfig, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return chainConfig{}, err
+	}
+	chain, ok := cfg.Chains[name]
+	if !ok {
+		return chainConfig{}, fmt.Errorf("chain %s not found in the config file", name)
+	}
+	return chain, nil
+}
+
+// resolveBlockchainID returns the blockchain ID of the named chain in the config file, or
+// parses the string as a cb58 or hex blockchain ID if there is no chain by that name.
+func resolveBlockchainID(nameOrID string) (ids.ID, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return ids.Empty, err
+	}
+	if chain, ok := cfg.Chains[nameOrID]; ok {
+		if chain.BlockchainID == "" {
+			return ids.Empty, fmt.Errorf("chain %s has no blockchain-id in the config file", nameOrID)
+		}
+		return parseBlockchainID(chain.BlockchainID)
+	}
+	return parseBlockchainID(nameOrID)
+}
+
+// setFlagDefaults sets the flags of cmd named by the keys of values, unless they were set
+// on the command line or the value is empty. Flags that the command does not define are skipped.
+func setFlagDefaults(cmd *cobra.Command, values map[string]string) error {
+	for name, value := range values {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed || value == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("invalid %s in the config file: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applyChainProfile fills in the connection flags of a single chain command from the --chain profile
+func applyChainProfile(cmd *cobra.Command) error {
+	if chainName == "" {
+		return nil
+	}
+	chain, err := getChainConfig(chainName)
+	if err != nil {
+		return err
+	}
+	return setFlagDefaults(cmd, map[string]string{
+		"rpc":                         chain.RPCURL,
+		"ws":                          chain.WSURL,
+		"teleporter-address":          chain.TeleporterAddress,
+		"teleporter-registry-address": chain.TeleporterRegistryAddress,
+		"node-uri":                    chain.NodeURI,
+	})
+}
+
+// applyChainsProfile fills in the endpoints of a multi chain command from the --chain profiles.
+// urlFlag is the slice flag that takes the endpoints, either "ws" or "rpc".
+func applyChainsProfile(cmd *cobra.Command, urlFlag string) error {
+	if cmd.Flags().Changed(urlFlag) {
+		return nil
+	}
+	for _, name := range chainNames {
+		chain, err := getChainConfig(name)
+		if err != nil {
+			return err
+		}
+		url, key := chain.WSURL, "ws-url"
+		if urlFlag == "rpc" {
+			url, key = chain.RPCURL, "rpc-url"
+		}
+		if url == "" {
+			return fmt.Errorf("chain %s has no %s in the config file", name, key)
+		}
+		if err := setFlagDefaults(cmd, map[string]string{"teleporter-address": chain.TeleporterAddress}); err != nil {
+			return err
+		}
+		// Setting a slice flag that was already set appends to it.
+		if err := cmd.Flags().Set(urlFlag, url); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyFromToProfiles fills in the source and destination flags of a cross chain command
+// from the --from and --to profiles
+func applyFromToProfiles(cmd *cobra.Command) error {
+	if fromChainName != "" {
+		chain, err := getChainConfig(fromChainName)
+		if err != nil {
+			return err
+		}
+		err = setFlagDefaults(cmd, map[string]string{
+			"source-rpc":         chain.RPCURL,
+			"teleporter-address": chain.TeleporterAddress,
+			"node-uri":           chain.NodeURI,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if toChainName != "" {
+		chain, err := getChainConfig(toChainName)
+		if err != nil {
+			return err
+		}
+		err = setFlagDefaults(cmd, map[string]string{
+			"dest-rpc":           chain.RPCURL,
+			"teleporter-address": chain.TeleporterAddress,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addChainFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&chainName, "chain", "",
+		"Name of a chain in the config file to take the connection flags from")
+}
+
+func addChainsFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVar(&chainNames, "chain", []string{},
+		"Names of chains in the config file to take the connection flags from")
+}
+
+func addFromToFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&fromChainName, "from", "",
+		"Name of the source chain in the config file to take the source connection flags from")
+	cmd.PersistentFlags().StringVar(&toChainName, "to", "",
+		"Name of the destination chain in the config file to take the destination connection flags from")
+}
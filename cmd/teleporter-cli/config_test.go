@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestConfig(t *testing.T, contents string) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	configFile = path
+	config = nil
+	t.Cleanup(func() {
+		configFile = ""
+		config = nil
+		chainName = ""
+		chainNames = []string{}
+		fromChainName = ""
+		toChainName = ""
+	})
+}
+
+func TestChainProfiles(t *testing.T) {
+	blockchainIDA := ids.ID{1}
+	writeTestConfig(t, fmt.Sprintf(`
+chains:
+  subnet-a:
+    rpc-url: http://127.0.0.1:9650/ext/bc/A/rpc
+    ws-url: ws://127.0.0.1:9650/ext/bc/A/ws
+    blockchain-id: %s
+    teleporter-address: "0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf"
+    node-uri: http://127.0.0.1:9650
+  subnet-b:
+    rpc-url: http://127.0.0.1:9650/ext/bc/B/rpc
+    teleporter-address: "0x0000000000000000000000000000000000000001"
+`, blockchainIDA))
+
+	newCmd := func(flags ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		for _, flag := range flags {
+			cmd.Flags().String(flag, "", "")
+		}
+		return cmd
+	}
+
+	t.Run("chain", func(t *testing.T) {
+		cmd := newCmd("rpc", "teleporter-address")
+		require.NoError(t, cmd.Flags().Set("teleporter-address", "0x02"))
+		chainName = "subnet-a"
+		require.NoError(t, applyChainProfile(cmd))
+
+		rpc, err := cmd.Flags().GetString("rpc")
+		require.NoError(t, err)
+		require.Equal(t, "http://127.0.0.1:9650/ext/bc/A/rpc", rpc)
+		// Flags set on the command line take precedence over the profile
+		address, err := cmd.Flags().GetString("teleporter-address")
+		require.NoError(t, err)
+		require.Equal(t, "0x02", address)
+	})
+
+	t.Run("from and to", func(t *testing.T) {
+		cmd := newCmd("source-rpc", "dest-rpc", "teleporter-address", "node-uri")
+		fromChainName = "subnet-a"
+		toChainName = "subnet-b"
+		require.NoError(t, applyFromToProfiles(cmd))
+
+		for flag, expected := range map[string]string{
+			"source-rpc":         "http://127.0.0.1:9650/ext/bc/A/rpc",
+			"dest-rpc":           "http://127.0.0.1:9650/ext/bc/B/rpc",
+			"teleporter-address": "0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf",
+			"node-uri":           "http://127.0.0.1:9650",
+		} {
+			value, err := cmd.Flags().GetString(flag)
+			require.NoError(t, err)
+			require.Equal(t, expected, value, flag)
+		}
+	})
+
+	t.Run("chains", func(t *testing.T) {
+		cmd := newCmd("teleporter-address")
+		cmd.Flags().StringSlice("ws", []string{}, "")
+		chainNames = []string{"subnet-a"}
+		require.NoError(t, applyChainsProfile(cmd, "ws"))
+		ws, err := cmd.Flags().GetStringSlice("ws")
+		require.NoError(t, err)
+		require.Equal(t, []string{"ws://127.0.0.1:9650/ext/bc/A/ws"}, ws)
+
+		cmd = newCmd("teleporter-address")
+		cmd.Flags().StringSlice("rpc", []string{}, "")
+		chainNames = []string{"subnet-a", "subnet-b"}
+		require.NoError(t, applyChainsProfile(cmd, "rpc"))
+		rpc, err := cmd.Flags().GetStringSlice("rpc")
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"http://127.0.0.1:9650/ext/bc/A/rpc",
+			"http://127.0.0.1:9650/ext/bc/B/rpc",
+		}, rpc)
+
+		// subnet-b has no websocket endpoint
+		chainNames = []string{"subnet-b"}
+		require.ErrorContains(t, applyChainsProfile(newCmd("ws"), "ws"), "chain subnet-b has no ws-url")
+	})
+
+	t.Run("unknown chain", func(t *testing.T) {
+		chainName = "subnet-c"
+		require.ErrorContains(t, applyChainProfile(newCmd("rpc")), "chain subnet-c not found")
+	})
+
+	t.Run("resolve blockchain ID", func(t *testing.T) {
+		id, err := resolveBlockchainID("subnet-a")
+		require.NoError(t, err)
+		require.Equal(t, blockchainIDA, id)
+
+		id, err = resolveBlockchainID(ids.ID{2}.String())
+		require.NoError(t, err)
+		require.Equal(t, ids.ID{2}, id)
+
+		_, err = resolveBlockchainID("subnet-b")
+		require.ErrorContains(t, err, "chain subnet-b has no blockchain-id")
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	// A missing config file is only an error if it was set explicitly
+	writeTestConfig(t, "")
+	configFile = filepath.Join(t.TempDir(), "missing.yaml")
+	_, err := loadConfig()
+	require.Error(t, err)
+
+	// Chain names are not taken for blockchain IDs when the config file is missing
+	_, err = resolveBlockchainID("subnet-a")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// Blockchain IDs are resolved without the default config file
+	writeTestConfig(t, "")
+	configFile = ""
+	t.Setenv("HOME", t.TempDir())
+	id, err := resolveBlockchainID(ids.ID{2}.String())
+	require.NoError(t, err)
+	require.Equal(t, ids.ID{2}, id)
+
+	writeTestConfig(t, "chains: [")
+	_, err = loadConfig()
+	require.ErrorContains(t, err, "failed to parse config file")
+	_, err = resolveBlockchainID(ids.ID{2}.String())
+	require.ErrorContains(t, err, "failed to parse config file")
+}
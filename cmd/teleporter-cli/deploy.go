@@ -0,0 +1,275 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	deploymentUtils "github.com/ava-labs/teleporter/utils/deployment-utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	keylessTxFiles  deploymentUtils.KeylessTransactionFiles
+	deployByteCode  string
+	deployKeylessTx string
+
+	errNotKeylessTx   = errors.New("not a keyless contract creation transaction")
+	errDeployerUsed   = errors.New("deployer address has already sent a transaction")
+	errNoDeployedCode = errors.New("no contract code at the contract address")
+)
+
+// keylessTxOutput is a keyless contract creation transaction, and the addresses it deploys from and to
+type keylessTxOutput struct {
+	Transaction     string `json:"transaction"`
+	DeployerAddress string `json:"deployerAddress"`
+	ContractAddress string `json:"contractAddress"`
+}
+
+type deriveAddressOutput struct {
+	DeployerAddress string `json:"deployerAddress"`
+	Nonce           uint64 `json:"nonce"`
+	ContractAddress string `json:"contractAddress"`
+}
+
+// deployOutput is the result of a deploy teleporter command. Deployed is false if the contract
+// already existed, in which case no transactions were sent.
+type deployOutput struct {
+	DeployerAddress  string `json:"deployerAddress"`
+	ContractAddress  string `json:"contractAddress"`
+	Deployed         bool   `json:"deployed"`
+	FundingTxHash    string `json:"fundingTxHash,omitempty"`
+	DeploymentTxHash string `json:"deploymentTxHash,omitempty"`
+}
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Deploys the TeleporterMessenger contract with a keyless transaction",
+	Long: `Builds and sends the keyless contract creation transaction that deploys the
+TeleporterMessenger contract to the same address on every EVM chain, using
+Nick's method. The transaction is signed with fixed signature values instead
+of a private key, so its sender, the deployer address, is recovered from the
+signature and has never sent a transaction. Deploying the same bytecode from
+the deployer address with nonce 0 results in the same contract address on
+every chain.`,
+}
+
+var deployKeylessTxCmd = &cobra.Command{
+	Use:   "keyless-tx BYTECODE_FILE",
+	Short: "Builds the keyless transaction that deploys a contract",
+	Long: `Builds the keyless contract creation transaction for the bytecode of a forge
+build output JSON file, i.e.
+contracts/out/TeleporterMessenger.sol/TeleporterMessenger.json, and prints
+the raw transaction, the deployer address that must be funded to send it, and
+the contract address it deploys to. Each value is also written to a file if
+its output path is set.`,
+	Args: cobra.ExactArgs(1),
+	Run:  deployKeylessTxRun,
+}
+
+var deployDeriveAddressCmd = &cobra.Command{
+	Use:   "derive-address DEPLOYER_ADDRESS NONCE",
+	Short: "Derives the address of a contract created by an address",
+	Long: `Derives the address of the contract created by the transaction with the given
+nonce from the deployer address.`,
+	Args: cobra.ExactArgs(2),
+	Run:  deployDeriveAddressRun,
+}
+
+var deployTeleporterCmd = &cobra.Command{
+	Use: "teleporter --rpc RPC_URL (--bytecode BYTECODE_FILE | --keyless-tx TX_FILE) " +
+		"[--private-key KEY | --keystore FILE | --external-signer URL]",
+	Short: "Deploys the TeleporterMessenger contract to a chain",
+	Long: `Deploys the TeleporterMessenger contract with the keyless transaction built from
+--bytecode, or read from --keyless-tx as written by deploy keyless-tx. The
+deployer address is funded by the signer, if one is set, with the balance
+missing to pay for the transaction, then the raw transaction is broadcast,
+and the contract code is verified at the universal contract address.
+Deployment is skipped if the contract already exists, so the command can be
+run again safely.`,
+	Args: cobra.NoArgs,
+	Run:  deployTeleporterRun,
+}
+
+func deployKeylessTxRun(cmd *cobra.Command, args []string) {
+	byteCode, err := deploymentUtils.ExtractByteCode(args[0])
+	cobra.CheckErr(err)
+	txBytes, deployerAddress, contractAddress, err := deploymentUtils.ConstructKeylessTransactionFromByteCode(byteCode)
+	cobra.CheckErr(err)
+	err = deploymentUtils.WriteKeylessTransactionFiles(keylessTxFiles, txBytes, deployerAddress, contractAddress)
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, keylessTxOutput{
+		Transaction:     hexutil.Encode(txBytes),
+		DeployerAddress: deployerAddress.Hex(),
+		ContractAddress: contractAddress.Hex(),
+	})
+	cmd.Println("Deploy keyless-tx command ran successfully")
+}
+
+func deployDeriveAddressRun(cmd *cobra.Command, args []string) {
+	deployerAddress, nonce, err := parseDeriveAddressArgs(args)
+	cobra.CheckErr(err)
+	contractAddress, err := deploymentUtils.DeriveEVMContractAddress(deployerAddress, nonce)
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, deriveAddressOutput{
+		DeployerAddress: deployerAddress.Hex(),
+		Nonce:           nonce,
+		ContractAddress: contractAddress.Hex(),
+	})
+	cmd.Println("Deploy derive-address command ran successfully")
+}
+
+func deployTeleporterRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	var txBytes []byte
+	if deployByteCode != "" {
+		byteCode, err := deploymentUtils.ExtractByteCode(deployByteCode)
+		cobra.CheckErr(err)
+		txBytes, _, _, err = deploymentUtils.ConstructKeylessTransactionFromByteCode(byteCode)
+		cobra.CheckErr(err)
+	} else {
+		b, err := readInputFile(cmd, deployKeylessTx)
+		cobra.CheckErr(err)
+		txBytes, err = hexutil.Decode(ensureHexPrefix(strings.TrimSpace(string(b))))
+		cobra.CheckErr(err)
+	}
+	tx, deployerAddress, contractAddress, err := parseKeylessTransaction(txBytes)
+	cobra.CheckErr(err)
+	out := deployOutput{
+		DeployerAddress: deployerAddress.Hex(),
+		ContractAddress: contractAddress.Hex(),
+	}
+
+	code, err := client.CodeAt(ctx, contractAddress, nil)
+	cobra.CheckErr(err)
+	if len(code) > 0 {
+		logger.Info("Contract is already deployed, skipping deployment",
+			zap.String("contractAddress", contractAddress.Hex()))
+		writeOutput(cmd, out)
+		cmd.Println("Deploy teleporter command ran successfully")
+		return
+	}
+	// The keyless transaction can only be sent with nonce 0, so the contract address can no
+	// longer be deployed to once the deployer has sent any other transaction.
+	nonce, err := client.NonceAt(ctx, deployerAddress, nil)
+	cobra.CheckErr(err)
+	if nonce > 0 {
+		cobra.CheckErr(fmt.Errorf("%w: %s has nonce %d, but %s has no code, so it can not be deployed to",
+			errDeployerUsed, deployerAddress, nonce, contractAddress))
+	}
+
+	balance, err := client.BalanceAt(ctx, deployerAddress, nil)
+	cobra.CheckErr(err)
+	if missing := new(big.Int).Sub(tx.Cost(), balance); missing.Sign() > 0 {
+		signer, err := newSigner(cmd)
+		if errors.Is(err, errMissingSigner) {
+			cobra.CheckErr(fmt.Errorf("deployer %s needs %s more to pay for the deployment, set a signer to fund it: %w",
+				deployerAddress, missing, err))
+		}
+		cobra.CheckErr(err)
+		logger.Info("Funding deployer",
+			zap.String("deployerAddress", deployerAddress.Hex()),
+			zap.String("amount", missing.String()))
+		receipt, err := sendTransaction(ctx, client, signer, deployerAddress, missing, nil)
+		cobra.CheckErr(err)
+		out.FundingTxHash = receipt.TxHash.Hex()
+	}
+
+	// A previous run may have broadcast the transaction without waiting for it to be accepted.
+	if err := client.SendTransaction(ctx, tx); err != nil && !strings.Contains(err.Error(), "already known") {
+		cobra.CheckErr(fmt.Errorf("failed to send keyless transaction: %w", err))
+	}
+	logger.Info("Sent keyless transaction, waiting for acceptance", zap.String("txHash", tx.Hash().Hex()))
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	cobra.CheckErr(err)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		cobra.CheckErr(fmt.Errorf("keyless transaction %s failed", tx.Hash().Hex()))
+	}
+	out.Deployed = true
+	out.DeploymentTxHash = receipt.TxHash.Hex()
+
+	code, err = client.CodeAt(ctx, contractAddress, nil)
+	cobra.CheckErr(err)
+	if len(code) == 0 {
+		cobra.CheckErr(fmt.Errorf("%w %s", errNoDeployedCode, contractAddress))
+	}
+
+	writeOutput(cmd, out)
+	cmd.Println("Deploy teleporter command ran successfully")
+}
+
+// parseDeriveAddressArgs parses the deployer address and nonce arguments of derive-address
+func parseDeriveAddressArgs(args []string) (common.Address, uint64, error) {
+	if !common.IsHexAddress(args[0]) {
+		return common.Address{}, 0, fmt.Errorf("invalid deployer address %s", args[0])
+	}
+	nonce, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return common.Address{}, 0, fmt.Errorf("invalid nonce %s: %w", args[1], err)
+	}
+	return common.HexToAddress(args[0]), nonce, nil
+}
+
+// parseKeylessTransaction decodes a raw keyless contract creation transaction, and returns the
+// deployer address recovered from its signature and the address of the contract it creates
+func parseKeylessTransaction(txBytes []byte) (*types.Transaction, common.Address, common.Address, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(txBytes); err != nil {
+		return nil, common.Address{}, common.Address{}, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	// The transaction must be valid on every chain, so it can not be replay protected.
+	if tx.Type() != types.LegacyTxType || tx.Protected() || tx.To() != nil || tx.Nonce() != 0 {
+		return nil, common.Address{}, common.Address{}, errNotKeylessTx
+	}
+	deployerAddress, err := types.HomesteadSigner{}.Sender(tx)
+	if err != nil {
+		return nil, common.Address{}, common.Address{}, fmt.Errorf("failed to recover deployer address: %w", err)
+	}
+	contractAddress, err := deploymentUtils.DeriveEVMContractAddress(deployerAddress, 0)
+	if err != nil {
+		return nil, common.Address{}, common.Address{}, err
+	}
+	return tx, deployerAddress, contractAddress, nil
+}
+
+func init() {
+	rootCmd.AddCommand(deployCmd)
+	deployCmd.AddCommand(deployKeylessTxCmd)
+	deployCmd.AddCommand(deployDeriveAddressCmd)
+	deployCmd.AddCommand(deployTeleporterCmd)
+	deployKeylessTxCmd.Flags().StringVar(&keylessTxFiles.Transaction, "tx-file", "",
+		"Path to write the raw transaction to")
+	deployKeylessTxCmd.Flags().StringVar(&keylessTxFiles.DeployerAddress, "deployer-address-file", "",
+		"Path to write the deployer address to")
+	deployKeylessTxCmd.Flags().StringVar(&keylessTxFiles.ContractAddress, "contract-address-file", "",
+		"Path to write the contract address to")
+
+	deployTeleporterCmd.Flags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint to connect to the node")
+	addChainFlag(deployTeleporterCmd)
+	deployTeleporterCmd.Flags().StringVar(&deployByteCode, "bytecode", "",
+		"Forge build output JSON file of the TeleporterMessenger contract")
+	deployTeleporterCmd.Flags().StringVar(&deployKeylessTx, "keyless-tx", "",
+		"File containing the hex encoded keyless transaction, or - to read it from stdin")
+	addSignerFlags(deployTeleporterCmd.Flags(), "to fund the deployer address with, if its balance is insufficient")
+	err := deployTeleporterCmd.MarkFlagRequired("rpc")
+	cobra.CheckErr(err)
+	deployTeleporterCmd.MarkFlagsOneRequired("bytecode", "keyless-tx")
+	deployTeleporterCmd.MarkFlagsMutuallyExclusive("bytecode", "keyless-tx")
+	deployTeleporterCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return rpcPreRunE(cmd, args, nil)
+	}
+}
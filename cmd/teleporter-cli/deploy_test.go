@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	deploymentUtils "github.com/ava-labs/teleporter/utils/deployment-utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployCmd(t *testing.T) {
+	dir := t.TempDir()
+	byteCodeFile := filepath.Join(dir, "Contract.json")
+	require.NoError(t, os.WriteFile(byteCodeFile, []byte(`{"bytecode": {"object": "0x6080604052"}}`), 0o600))
+	txFile := filepath.Join(dir, "tx.txt")
+	txBytes, deployerAddress, contractAddress, err :=
+		deploymentUtils.ConstructKeylessTransactionFromByteCode(common.FromHex("0x6080604052"))
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "keyless-tx no args",
+			args: []string{"deploy", "keyless-tx"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "derive-address no args",
+			args: []string{"deploy", "derive-address", "0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0"},
+			err:  fmt.Errorf("accepts 2 arg(s), received 1"),
+		},
+		{
+			name: "teleporter no transaction",
+			args: []string{"deploy", "teleporter", "--rpc", "http://127.0.0.1:9650"},
+			err:  fmt.Errorf("at least one of the flags in the group [bytecode keyless-tx] is required"),
+		},
+		{
+			name: "teleporter both transactions",
+			args: []string{"deploy", "teleporter", "--rpc", "http://127.0.0.1:9650",
+				"--bytecode", byteCodeFile, "--keyless-tx", txFile},
+			err: fmt.Errorf("if any flags in the group [bytecode keyless-tx] are set none of the others can be"),
+		},
+		{
+			name: "keyless-tx",
+			args: []string{"deploy", "keyless-tx", byteCodeFile, "--tx-file", txFile, "-o", "json"},
+			out:  `"contractAddress": "` + contractAddress.Hex() + `"`,
+		},
+		{
+			name: "derive-address",
+			args: []string{"deploy", "derive-address", "0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0", "1", "-o", "json"},
+			out:  `"contractAddress": "0x343c43A37D37dfF08AE8C4A11544c718AbB4fCF8"`,
+		},
+		{
+			name: "teleporter help",
+			args: []string{"deploy", "teleporter", "--help"},
+			out:  "Deployment is skipped if the contract already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+
+	// Only the paths that were set are written
+	b, err := os.ReadFile(txFile)
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(txBytes), string(b))
+	require.Equal(t, "", keylessTxFiles.DeployerAddress)
+	_, err = os.Stat(deploymentUtils.DefaultKeylessTransactionFiles.DeployerAddress)
+	require.True(t, os.IsNotExist(err))
+
+	// The written transaction is parsed back to the same addresses
+	tx, parsedDeployer, parsedContract, err := parseKeylessTransaction(common.FromHex(string(b)))
+	require.NoError(t, err)
+	require.Equal(t, deployerAddress, parsedDeployer)
+	require.Equal(t, contractAddress, parsedContract)
+	require.Equal(t, uint64(4000000), tx.Gas())
+}
+
+func TestParseDeriveAddressArgs(t *testing.T) {
+	address, nonce, err := parseDeriveAddressArgs([]string{"0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0", "7"})
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0"), address)
+	require.Equal(t, uint64(7), nonce)
+
+	_, _, err = parseDeriveAddressArgs([]string{"0x1234", "0"})
+	require.ErrorContains(t, err, "invalid deployer address 0x1234")
+	_, _, err = parseDeriveAddressArgs([]string{"0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0", "-1"})
+	require.ErrorContains(t, err, "invalid nonce -1")
+}
+
+func TestParseKeylessTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sign := func(signer types.Signer, txData types.TxData) []byte {
+		tx, err := types.SignNewTx(key, signer, txData)
+		require.NoError(t, err)
+		b, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		return b
+	}
+	creation := &types.LegacyTx{Gas: 100000, GasPrice: big.NewInt(1), Data: []byte{0x60}}
+
+	// A transaction signed with a private key is keyless as long as it is not replay protected
+	_, deployerAddress, contractAddress, err := parseKeylessTransaction(sign(types.HomesteadSigner{}, creation))
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), deployerAddress)
+	require.Equal(t, crypto.CreateAddress(deployerAddress, 0), contractAddress)
+
+	_, _, _, err = parseKeylessTransaction(sign(types.NewEIP155Signer(big.NewInt(1)), creation))
+	require.ErrorIs(t, err, errNotKeylessTx)
+	to := common.HexToAddress("0x01")
+	_, _, _, err = parseKeylessTransaction(sign(types.HomesteadSigner{},
+		&types.LegacyTx{To: &to, Gas: 21000, GasPrice: big.NewInt(1)}))
+	require.ErrorIs(t, err, errNotKeylessTx)
+	_, _, _, err = parseKeylessTransaction(sign(types.HomesteadSigner{},
+		&types.LegacyTx{Nonce: 1, Gas: 100000, GasPrice: big.NewInt(1)}))
+	require.ErrorIs(t, err, errNotKeylessTx)
+	_, _, _, err = parseKeylessTransaction([]byte{1, 2, 3})
+	require.ErrorContains(t, err, "failed to decode transaction")
+}
@@ -0,0 +1,158 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	encodeTeleporterAddress  string
+	encodeNetworkID          uint32
+	encodeSourceBlockchainID string
+
+	errMissingEncodeTeleporterAddress = errors.New(
+		"--teleporter-address is required to wrap the message in a Warp message")
+)
+
+// messageInput is the input representation of a TeleporterMessage. It accepts the same
+// schema as the message output, so that decoded messages can be edited and re-encoded.
+type messageInput struct {
+	MessageID               bigIntInput       `json:"messageID"`
+	SenderAddress           common.Address    `json:"senderAddress"`
+	DestinationBlockchainID blockchainIDInput `json:"destinationBlockchainID"`
+	DestinationAddress      common.Address    `json:"destinationAddress"`
+	RequiredGasLimit        bigIntInput       `json:"requiredGasLimit"`
+	AllowedRelayerAddresses []common.Address  `json:"allowedRelayerAddresses"`
+	Receipts                []struct {
+		ReceivedMessageID    bigIntInput    `json:"receivedMessageID"`
+		RelayerRewardAddress common.Address `json:"relayerRewardAddress"`
+	} `json:"receipts"`
+	Message hexutil.Bytes `json:"message"`
+}
+
+// encodeOutput is the result of an encode command. The Warp fields are only set if
+// the message was wrapped in a Warp message.
+type encodeOutput struct {
+	Message             string `json:"message"`
+	AddressedCall       string `json:"addressedCall,omitempty"`
+	UnsignedWarpMessage string `json:"unsignedWarpMessage,omitempty"`
+	WarpMessageID       string `json:"warpMessageID,omitempty"`
+}
+
+var encodeCmd = &cobra.Command{
+	Use:   "encode INPUT_FILE",
+	Short: "Encodes a JSON or YAML TeleporterMessage into its ABI encoded bytes",
+	Long: `Given a file containing a TeleporterMessage in JSON or YAML, using the same schema
+as the output of the message command, this command ABI encodes the message
+and prints the hex encoded bytes. Use - to read the message from stdin.
+Big integers may be given as numbers or as decimal or hex strings, and
+blockchain IDs in cb58 or hex. If --teleporter-address is set, the bytes are
+also wrapped in a Warp AddressedCall sent by that address. If
+--source-blockchain-id is set, the AddressedCall is also wrapped in an
+unsigned Warp message from that chain on --network-id.`,
+	Args: cobra.ExactArgs(1),
+	Run:  encodeRun,
+}
+
+func encodeRun(cmd *cobra.Command, args []string) {
+	b, err := readInputFile(cmd, args[0])
+	cobra.CheckErr(err)
+
+	message, err := parseMessageInput(b)
+	cobra.CheckErr(err)
+	messageBytes, err := teleportermessenger.PackTeleporterMessage(message)
+	cobra.CheckErr(err)
+	out := encodeOutput{Message: hexutil.Encode(messageBytes)}
+
+	if encodeTeleporterAddress == "" {
+		if encodeSourceBlockchainID != "" {
+			cobra.CheckErr(errMissingEncodeTeleporterAddress)
+		}
+		writeOutput(cmd, out)
+		cmd.Println("Encode command ran successfully")
+		return
+	}
+
+	addressedCall, err := warpPayload.NewAddressedCall(
+		common.HexToAddress(encodeTeleporterAddress).Bytes(),
+		messageBytes,
+	)
+	cobra.CheckErr(err)
+	out.AddressedCall = hexutil.Encode(addressedCall.Bytes())
+
+	if encodeSourceBlockchainID != "" {
+		sourceBlockchainID, err := resolveBlockchainID(encodeSourceBlockchainID)
+		cobra.CheckErr(err)
+		unsignedMsg, err := avalancheWarp.NewUnsignedMessage(encodeNetworkID, sourceBlockchainID, addressedCall.Bytes())
+		cobra.CheckErr(err)
+		warpMessageID := unsignedMsg.ID()
+		out.UnsignedWarpMessage = hexutil.Encode(unsignedMsg.Bytes())
+		out.WarpMessageID = hexutil.Encode(warpMessageID[:])
+	}
+
+	writeOutput(cmd, out)
+	cmd.Println("Encode command ran successfully")
+}
+
+// parseMessageInput parses a JSON or YAML TeleporterMessage
+func parseMessageInput(b []byte) (teleportermessenger.TeleporterMessage, error) {
+	var input messageInput
+	if err := unmarshalInput(b, &input); err != nil {
+		return teleportermessenger.TeleporterMessage{}, fmt.Errorf("failed to parse message: %w", err)
+	}
+
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               input.MessageID.Int,
+		SenderAddress:           input.SenderAddress,
+		DestinationBlockchainID: input.DestinationBlockchainID.ID,
+		DestinationAddress:      input.DestinationAddress,
+		RequiredGasLimit:        input.RequiredGasLimit.Int,
+		AllowedRelayerAddresses: input.AllowedRelayerAddresses,
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 input.Message,
+	}
+	if message.MessageID == nil {
+		message.MessageID = big.NewInt(0)
+	}
+	if message.RequiredGasLimit == nil {
+		message.RequiredGasLimit = big.NewInt(0)
+	}
+	if message.AllowedRelayerAddresses == nil {
+		message.AllowedRelayerAddresses = []common.Address{}
+	}
+	for _, receipt := range input.Receipts {
+		receivedMessageID := receipt.ReceivedMessageID.Int
+		if receivedMessageID == nil {
+			receivedMessageID = big.NewInt(0)
+		}
+		message.Receipts = append(message.Receipts, teleportermessenger.TeleporterMessageReceipt{
+			ReceivedMessageID:    receivedMessageID,
+			RelayerRewardAddress: receipt.RelayerRewardAddress,
+		})
+	}
+	if message.Message == nil {
+		message.Message = []byte{}
+	}
+	return message, nil
+}
+
+func init() {
+	rootCmd.AddCommand(encodeCmd)
+	encodeCmd.Flags().StringVarP(&encodeTeleporterAddress, "teleporter-address", "t", "",
+		"Teleporter contract address to send the Warp AddressedCall from")
+	encodeCmd.Flags().Uint32Var(&encodeNetworkID, "network-id", 0, "Network ID of the unsigned Warp message")
+	encodeCmd.Flags().StringVar(&encodeSourceBlockchainID, "source-blockchain-id", "",
+		"Source blockchain ID of the unsigned Warp message, in cb58 or hex, or chain name")
+	encodeCmd.MarkFlagsRequiredTogether("network-id", "source-blockchain-id")
+}
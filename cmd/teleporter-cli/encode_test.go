@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"encode"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"encode", "--help"},
+			err:  nil,
+			out:  "Given a file containing a TeleporterMessage in JSON or YAML",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestParseMessageInput(t *testing.T) {
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(12),
+		SenderAddress:           common.HexToAddress("0x1234"),
+		DestinationBlockchainID: ids.ID{3, 4},
+		DestinationAddress:      common.HexToAddress("0x5678"),
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{common.HexToAddress("0x9abc")},
+		Receipts: []teleportermessenger.TeleporterMessageReceipt{
+			{ReceivedMessageID: big.NewInt(7), RelayerRewardAddress: common.HexToAddress("0xdef0")},
+		},
+		Message: []byte{1, 2, 3},
+	}
+
+	// The output of the message command is accepted as input
+	outputJSON, err := json.Marshal(newMessageOutput(message))
+	require.NoError(t, err)
+	parsed, err := parseMessageInput(outputJSON)
+	require.NoError(t, err)
+	require.Equal(t, message, parsed)
+
+	// YAML with numeric integers and a cb58 blockchain ID
+	yamlInput := fmt.Sprintf(`
+messageID: 12
+senderAddress: "0x0000000000000000000000000000000000001234"
+destinationBlockchainID: %s
+destinationAddress: "0x0000000000000000000000000000000000005678"
+requiredGasLimit: "0x186a0"
+allowedRelayerAddresses:
+  - "0x0000000000000000000000000000000000009abc"
+receipts:
+  - receivedMessageID: 7
+    relayerRewardAddress: "0x000000000000000000000000000000000000def0"
+message: "0x010203"
+`, ids.ID{3, 4})
+	parsed, err = parseMessageInput([]byte(yamlInput))
+	require.NoError(t, err)
+	require.Equal(t, message, parsed)
+
+	// YAML with unquoted hex values, which YAML parses as integers
+	yamlInput = fmt.Sprintf(`
+messageID: 0x0c
+senderAddress: 0x0000000000000000000000000000000000001234
+destinationBlockchainID: %s
+destinationAddress: 0x0000000000000000000000000000000000005678
+requiredGasLimit: 100000
+allowedRelayerAddresses:
+  - 0x0000000000000000000000000000000000009abc
+receipts:
+  - receivedMessageID: 07
+    relayerRewardAddress: 0x000000000000000000000000000000000000def0
+message: 0x010203
+`, common.Hash(ids.ID{3, 4}).Hex())
+	parsed, err = parseMessageInput([]byte(yamlInput))
+	require.NoError(t, err)
+	require.Equal(t, message, parsed)
+
+	// Omitted fields are encoded as zero values
+	parsed, err = parseMessageInput([]byte(`{"messageID": "1"}`))
+	require.NoError(t, err)
+	_, err = teleportermessenger.PackTeleporterMessage(parsed)
+	require.NoError(t, err)
+
+	_, err = parseMessageInput([]byte(`{"messageID": "abc"}`))
+	require.ErrorContains(t, err, "invalid integer abc")
+}
+
+func TestEncodeWarpMessage(t *testing.T) {
+	defer func() {
+		encodeTeleporterAddress = ""
+		encodeNetworkID = 0
+		encodeSourceBlockchainID = ""
+		outputFormat = textFormat
+		encodeCmd.SetOut(nil)
+	}()
+
+	inputFile := filepath.Join(t.TempDir(), "message.json")
+	require.NoError(t, os.WriteFile(inputFile, []byte(`{"messageID": "5", "message": "0xff"}`), 0o600))
+	sourceBlockchainID := ids.ID{9}
+	teleporter := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	encodeTeleporterAddress = teleporter.Hex()
+	encodeNetworkID = 12345
+	encodeSourceBlockchainID = sourceBlockchainID.String()
+	outputFormat = jsonFormat
+	buf := new(bytes.Buffer)
+	encodeCmd.SetOut(buf)
+	encodeRun(encodeCmd, []string{inputFile})
+
+	var result encodeOutput
+	require.NoError(t, json.NewDecoder(buf).Decode(&result))
+
+	unsignedMsgBytes, err := hexutil.Decode(result.UnsignedWarpMessage)
+	require.NoError(t, err)
+	unsignedMsg, err := avalancheWarp.ParseUnsignedMessage(unsignedMsgBytes)
+	require.NoError(t, err)
+	require.Equal(t, uint32(12345), unsignedMsg.NetworkID)
+	require.Equal(t, sourceBlockchainID, unsignedMsg.SourceChainID)
+	warpMessageID := unsignedMsg.ID()
+	require.Equal(t, hexutil.Encode(warpMessageID[:]), result.WarpMessageID)
+
+	addressedCall, err := warpPayload.ParseAddressedCall(unsignedMsg.Payload)
+	require.NoError(t, err)
+	require.Equal(t, teleporter.Bytes(), addressedCall.SourceAddress)
+	require.Equal(t, result.Message, hexutil.Encode(addressedCall.Payload))
+
+	message, err := teleportermessenger.UnpackTeleporterMessage(addressedCall.Payload)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5), message.MessageID)
+	require.Equal(t, []byte{0xff}, message.Message)
+}
@@ -4,47 +4,168 @@
 package main
 
 import (
-	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
 var (
-	topicArgs []string
-	data      []byte
+	topicArgs              []string
+	data                   []byte
+	eventFile              string
+	eventTeleporterAddress string
+
+	errNoLogs = errors.New("no logs found in the input")
 )
 
+// logInput is a log object as returned by eth_getLogs and eth_getTransactionReceipt. Only the
+// address, topics and data are required, and the numbers may also be decimal.
+type logInput struct {
+	Address     common.Address `json:"address"`
+	Topics      []common.Hash  `json:"topics"`
+	Data        hexutil.Bytes  `json:"data"`
+	BlockNumber bigIntInput    `json:"blockNumber"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	LogIndex    bigIntInput    `json:"logIndex"`
+}
+
 var eventCmd = &cobra.Command{
-	Use:   "event --topics topic1,topic2 [--data data]",
-	Short: "Parses a Teleporter log's topics and data",
+	Use:   "event (--topics topic1,topic2 [--data data] | --file LOGS_FILE) [--teleporter-address CONTRACT_ADDRESS]",
+	Short: "Parses Teleporter logs from their topics and data, or from log JSON",
 	Long: `Given the topics and data of a Teleporter log, parses the log into
 the corresponding Teleporter event. Topics are represented by a hash,
-and data is the hex encoding of the bytes.`,
+and data is the hex encoding of the bytes.
+
+Alternatively, --file reads log objects as returned by eth_getLogs or
+eth_getTransactionReceipt in JSON or YAML, and parses every Teleporter log
+in it. The file may hold a single log, a list of logs, a receipt or a list
+of receipts, optionally wrapped in a JSON-RPC response. Use - to read the
+logs from stdin. Logs emitted by other contracts are skipped. Logs are
+identified as Teleporter logs by --teleporter-address if set, or else by
+the event IDs of the Teleporter ABI.`,
 	Args: cobra.NoArgs,
 	Run:  eventRun,
 }
 
 func eventRun(cmd *cobra.Command, args []string) {
-	var topics []common.Hash
-	for _, topic := range topicArgs {
-		topics = append(topics, common.HexToHash(topic))
+	if eventFile == "" {
+		var topics []common.Hash
+		for _, topic := range topicArgs {
+			topics = append(topics, common.HexToHash(topic))
+		}
+		name, event, err := parseTeleporterLog(topics, data)
+		cobra.CheckErr(err)
+		writeOutput(cmd, newEventOutput(name, event, nil))
+		cmd.Println("Event command ran successfully for", name)
+		return
 	}
 
-	event, err := teleporterABI.EventByID(topics[0])
+	teleporterAddress = common.HexToAddress(eventTeleporterAddress)
+	b, err := readInputFile(cmd, eventFile)
 	cobra.CheckErr(err)
-
-	out, err := teleportermessenger.FilterTeleporterEvents(topics, data, event.Name)
+	logs, err := parseLogsInput(b)
 	cobra.CheckErr(err)
-	logger.Info("Parsed Teleporter event", zap.String("name", event.Name), zap.Any("event", out))
-	cmd.Println("Event command ran successfully for", event.Name)
+
+	out := decodeTeleporterLogs(logs)
+	writeOutput(cmd, out)
+	cmd.Printf("Event command ran successfully for %d of %d logs\n", len(out), len(logs))
+}
+
+// decodeTeleporterLogs decodes the Teleporter logs among logs. Logs of other contracts are
+// skipped, as are Teleporter logs that fail to decode.
+func decodeTeleporterLogs(logs []*types.Log) []eventOutput {
+	out := []eventOutput{}
+	for _, log := range logs {
+		if !isTeleporterLog(log) {
+			logger.Debug("Skipping log of another contract", zap.String("address", log.Address.Hex()))
+			continue
+		}
+		name, event, err := parseTeleporterLog(log.Topics, log.Data)
+		if err != nil {
+			logger.Warn("Failed to parse Teleporter log",
+				zap.String("txHash", log.TxHash.Hex()),
+				zap.Uint("logIndex", log.Index),
+				zap.Error(err))
+			continue
+		}
+		out = append(out, newEventOutput(name, event, log))
+	}
+	return out
+}
+
+// parseLogsInput parses a JSON or YAML log, list of logs, receipt or list of receipts. Any of
+// them may be the result of a JSON-RPC response.
+func parseLogsInput(b []byte) ([]*types.Log, error) {
+	var doc interface{}
+	if err := unmarshalInput(b, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse logs: %w", err)
+	}
+	if response, ok := doc.(map[string]interface{}); ok {
+		if result, ok := response["result"]; ok {
+			doc = result
+		}
+	}
+	items, ok := doc.([]interface{})
+	if !ok {
+		items = []interface{}{doc}
+	}
+	// Receipts are replaced by their logs.
+	var logItems []interface{}
+	for _, item := range items {
+		if receipt, ok := item.(map[string]interface{}); ok {
+			if receiptLogs, ok := receipt["logs"].([]interface{}); ok {
+				logItems = append(logItems, receiptLogs...)
+				continue
+			}
+		}
+		logItems = append(logItems, item)
+	}
+	if len(logItems) == 0 {
+		return nil, errNoLogs
+	}
+
+	jsonBytes, err := json.Marshal(logItems)
+	if err != nil {
+		return nil, err
+	}
+	var inputs []logInput
+	if err := json.Unmarshal(jsonBytes, &inputs); err != nil {
+		return nil, fmt.Errorf("failed to parse logs: %w", err)
+	}
+	logs := make([]*types.Log, 0, len(inputs))
+	for _, input := range inputs {
+		log := &types.Log{
+			Address: input.Address,
+			Topics:  input.Topics,
+			Data:    input.Data,
+			TxHash:  input.TxHash,
+		}
+		if input.BlockNumber.Int != nil {
+			log.BlockNumber = input.BlockNumber.Uint64()
+		}
+		if input.LogIndex.Int != nil {
+			log.Index = uint(input.LogIndex.Uint64())
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
 }
 
 func init() {
 	rootCmd.AddCommand(eventCmd)
 	eventCmd.PersistentFlags().StringSliceVar(&topicArgs, "topics", []string{}, "Topic hashes of the event")
 	eventCmd.Flags().BytesHexVar(&data, "data", []byte{}, "Hex encoded data of the event")
-
-	err := eventCmd.MarkPersistentFlagRequired("topics")
-	cobra.CheckErr(err)
+	eventCmd.Flags().StringVar(&eventFile, "file", "",
+		"JSON or YAML file of logs or receipts to parse, or - to read them from stdin")
+	eventCmd.Flags().StringVarP(&eventTeleporterAddress, "teleporter-address", "t", "",
+		"Teleporter contract address to identify Teleporter logs with")
+	eventCmd.MarkFlagsOneRequired("topics", "file")
+	eventCmd.MarkFlagsMutuallyExclusive("topics", "file")
+	eventCmd.MarkFlagsMutuallyExclusive("data", "file")
 }
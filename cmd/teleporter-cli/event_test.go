@@ -2,8 +2,14 @@ package main
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/core/types"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,7 +23,12 @@ func TestEventCmd(t *testing.T) {
 		{
 			name: "no args",
 			args: []string{"event"},
-			err:  fmt.Errorf("required flag(s) \"topics\" not set"),
+			err:  fmt.Errorf("at least one of the flags in the group [topics file] is required"),
+		},
+		{
+			name: "topics and file",
+			args: []string{"event", "--topics", "0x01", "--file", "-"},
+			err:  fmt.Errorf("if any flags in the group [topics file] are set none of the others can be"),
 		},
 		{
 			name: "help",
@@ -39,3 +50,130 @@ func TestEventCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLogsInput(t *testing.T) {
+	address := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	topic := common.HexToHash("0x01")
+	txHash := common.HexToHash("0x02")
+	log := fmt.Sprintf(`{"address": "%s", "topics": ["%s"], "data": "0x0102", "blockNumber": "0x10", `+
+		`"transactionHash": "%s", "logIndex": "0x3", "removed": false}`, address.Hex(), topic.Hex(), txHash.Hex())
+	expected := &types.Log{
+		Address:     address,
+		Topics:      []common.Hash{topic},
+		Data:        []byte{1, 2},
+		BlockNumber: 16,
+		TxHash:      txHash,
+		Index:       3,
+	}
+
+	var tests = []struct {
+		name  string
+		input string
+		logs  []*types.Log
+		err   bool
+	}{
+		{
+			name:  "log",
+			input: log,
+			logs:  []*types.Log{expected},
+		},
+		{
+			name:  "list of logs",
+			input: "[" + log + "," + log + "]",
+			logs:  []*types.Log{expected, expected},
+		},
+		{
+			name:  "receipt",
+			input: `{"status": "0x1", "logs": [` + log + `]}`,
+			logs:  []*types.Log{expected},
+		},
+		{
+			name:  "rpc response with list of receipts",
+			input: `{"jsonrpc": "2.0", "id": 1, "result": [{"logs": [` + log + `]}, {"logs": [` + log + `]}]}`,
+			logs:  []*types.Log{expected, expected},
+		},
+		{
+			name: "yaml with decimal numbers",
+			input: fmt.Sprintf("address: \"%s\"\ntopics: [\"%s\"]\ndata: \"0x0102\"\nblockNumber: 16\n"+
+				"transactionHash: \"%s\"\nlogIndex: 3\n", address.Hex(), topic.Hex(), txHash.Hex()),
+			logs: []*types.Log{expected},
+		},
+		{
+			name:  "receipt without logs",
+			input: `{"result": {"status": "0x1", "logs": []}}`,
+			err:   true,
+		},
+		{
+			name:  "invalid topic",
+			input: `{"address": "0x01", "topics": ["0x01"], "data": "0x"}`,
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, err := parseLogsInput([]byte(tt.input))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.logs, logs)
+		})
+	}
+}
+
+func TestDecodeTeleporterLogs(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	defer func() { teleporterAddress = common.Address{} }()
+
+	messenger := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	other := common.HexToAddress("0x01")
+	withAddress := func(log types.Log, address common.Address, index uint) *types.Log {
+		log.Address = address
+		log.Index = index
+		return &log
+	}
+	executed := packTeleporterLog(t, "MessageExecuted",
+		[]common.Hash{common.Hash(ids.ID{1}), common.BigToHash(big.NewInt(1))})
+	logs := []*types.Log{
+		withAddress(executed, messenger, 0),
+		// A foreign event
+		withAddress(types.Log{Topics: []common.Hash{{1}}}, other, 1),
+		// A Teleporter event with malformed data
+		withAddress(types.Log{Topics: executed.Topics[:1]}, messenger, 2),
+		withAddress(executed, other, 3),
+	}
+
+	var tests = []struct {
+		name              string
+		teleporterAddress common.Address
+		events            []uint
+	}{
+		{
+			name:              "teleporter address",
+			teleporterAddress: messenger,
+			events:            []uint{0},
+		},
+		{
+			name:   "event IDs",
+			events: []uint{0, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teleporterAddress = tt.teleporterAddress
+			out := decodeTeleporterLogs(logs)
+			events := []uint{}
+			for _, event := range out {
+				require.Equal(t, teleportermessenger.MessageExecuted.String(), event.Name)
+				events = append(events, *event.LogIndex)
+			}
+			require.Equal(t, tt.events, events)
+		})
+	}
+}
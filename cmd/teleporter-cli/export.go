@@ -0,0 +1,629 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	csvExportFormat    = "csv"
+	ndjsonExportFormat = "ndjson"
+
+	defaultCheckpointFileName = "checkpoint.json"
+)
+
+var (
+	exportRPCEndpoints   []string
+	exportFormat         string
+	exportDir            string
+	exportCheckpointFile string
+	exportFromBlock      uint64
+	exportToBlock        uint64
+	exportChunkSize      uint64
+)
+
+// exportCommonColumns are the leading CSV columns of every event type
+var exportCommonColumns = []string{
+	"blockchainID.cb58", "blockchainID.hex", "blockNumber", "blockTimestamp", "txHash", "logIndex", "gasUsed",
+}
+
+// exportEventColumns are the CSV columns of each event type, named by the dotted path of the field
+// in the event output. Lists, and message payloads decoded by a payload decoder, are JSON encoded.
+var exportEventColumns = map[string][]string{
+	"SendCrossChainMessage": concatColumns(
+		blockchainIDColumns("destinationBlockchainID"),
+		[]string{"messageID"},
+		messageColumns("message"),
+		[]string{"feeInfo.feeTokenAddress", "feeInfo.amount"},
+	),
+	"ReceiveCrossChainMessage": concatColumns(
+		blockchainIDColumns("originBlockchainID"),
+		[]string{"messageID", "deliverer", "rewardRedeemer"},
+		messageColumns("message"),
+	),
+	"AddFeeAmount": concatColumns(
+		blockchainIDColumns("destinationBlockchainID"),
+		[]string{"messageID", "updatedFeeInfo.feeTokenAddress", "updatedFeeInfo.amount"},
+	),
+	"MessageExecutionFailed": concatColumns(
+		blockchainIDColumns("originBlockchainID"),
+		[]string{"messageID"},
+		messageColumns("message"),
+	),
+	"MessageExecuted": concatColumns(
+		blockchainIDColumns("originBlockchainID"),
+		[]string{"messageID"},
+	),
+	"RelayerRewardsRedeemed": {"redeemer", "asset", "amount"},
+}
+
+func blockchainIDColumns(prefix string) []string {
+	return []string{prefix + ".cb58", prefix + ".hex"}
+}
+
+func messageColumns(prefix string) []string {
+	columns := concatColumns(
+		[]string{"messageID", "senderAddress"},
+		blockchainIDColumns("destinationBlockchainID"),
+		[]string{"destinationAddress", "requiredGasLimit", "allowedRelayerAddresses", "receipts", "message", "payload"},
+	)
+	for i, column := range columns {
+		columns[i] = prefix + "." + column
+	}
+	return columns
+}
+
+func concatColumns(columns ...[]string) []string {
+	var out []string
+	for _, c := range columns {
+		out = append(out, c...)
+	}
+	return out
+}
+
+// exportReader is the subset of ethclient.Client used to export the events of a chain
+type exportReader interface {
+	logFilterer
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// exportRecord is a decoded Teleporter event along with its block and transaction. It is
+// written as is to NDJSON files, and flattened into the event's columns in CSV files.
+type exportRecord struct {
+	BlockchainID   blockchainIDOutput `json:"blockchainID"`
+	BlockNumber    uint64             `json:"blockNumber"`
+	BlockTimestamp uint64             `json:"blockTimestamp"`
+	TxHash         string             `json:"txHash"`
+	LogIndex       uint               `json:"logIndex"`
+	GasUsed        uint64             `json:"gasUsed"`
+	Event          interface{}        `json:"event"`
+}
+
+// exportCheckpoint records the last block exported from each chain, keyed by cb58 blockchain ID,
+// and the size in bytes of each export file once those blocks were written, keyed by file name.
+// The checkpoint only applies to exports of the same format and Teleporter contract.
+type exportCheckpoint struct {
+	Format            string            `json:"format,omitempty"`
+	TeleporterAddress string            `json:"teleporterAddress,omitempty"`
+	LastBlocks        map[string]uint64 `json:"lastBlocks"`
+	FileSizes         map[string]int64  `json:"fileSizes"`
+}
+
+// exportChainOutput summarizes the export of a single chain
+type exportChainOutput struct {
+	BlockchainID blockchainIDOutput `json:"blockchainID"`
+	FromBlock    uint64             `json:"fromBlock"`
+	ToBlock      uint64             `json:"toBlock"`
+	Events       int                `json:"events"`
+}
+
+// exportFile is an output file of a single event type
+type exportFile struct {
+	file *os.File
+	csv  *csv.Writer
+}
+
+// exportWriter appends records to one file per event type in a directory
+type exportWriter struct {
+	dir    string
+	format string
+	files  map[string]*exportFile
+}
+
+var exportCmd = &cobra.Command{
+	Use: "export --rpc RPC_URL... --teleporter-address CONTRACT_ADDRESS --dir DIR " +
+		"[--format csv|ndjson] [--from-block BLOCK] [--to-block BLOCK]",
+	Short: "Exports the Teleporter events of chains to CSV or NDJSON files",
+	Long: `Exports every Teleporter event of each of the --rpc chains to files in --dir,
+one file per event type such as SendCrossChainMessage.csv. Each event is
+written with the blockchain ID, block number and timestamp, transaction hash,
+log index and the gas used by its transaction. CSV files have a fixed set of
+columns per event type, named by the dotted path of the field in the event
+output, with lists JSON encoded. NDJSON files hold one JSON object per line.
+
+Events are appended to existing files, and the last block exported from each
+chain is saved to a checkpoint file after every chunk of --chunk-size blocks,
+so that running the command again continues from where it stopped. The
+checkpoint file defaults to checkpoint.json in --dir. Chains without a
+checkpoint are exported from --from-block, and all chains up to --to-block,
+which defaults to the latest block. The checkpoint also records the size of
+each file, and the files are truncated back to it when the command continues,
+so that the events of a chunk that was interrupted before its checkpoint was
+saved are neither duplicated nor left partially written. A checkpoint only
+continues an export of the same --format and --teleporter-address.`,
+	Args: cobra.NoArgs,
+	Run:  exportRun,
+}
+
+func exportRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	checkpointPath := exportCheckpointFile
+	if checkpointPath == "" {
+		checkpointPath = filepath.Join(exportDir, defaultCheckpointFileName)
+	}
+	checkpoint, err := readExportCheckpoint(checkpointPath)
+	cobra.CheckErr(err)
+	cobra.CheckErr(checkpoint.resume(exportFormat, teleporterAddress))
+	w, err := newExportWriter(exportDir, exportFormat)
+	cobra.CheckErr(err)
+	cobra.CheckErr(w.truncate(checkpoint.FileSizes))
+	saveCheckpoint := func() error {
+		checkpoint.FileSizes, err = w.fileSizes()
+		if err != nil {
+			return err
+		}
+		return checkpoint.save(checkpointPath)
+	}
+	// Record the files as they are before anything is written, so that an interrupted first chunk
+	// is rolled back too.
+	cobra.CheckErr(saveCheckpoint())
+
+	out := []exportChainOutput{}
+	for _, endpoint := range exportRPCEndpoints {
+		c, err := ethclient.Dial(endpoint)
+		cobra.CheckErr(err)
+		blockchainID, err := getBlockchainID(ctx, c)
+		cobra.CheckErr(err)
+
+		fromBlock := exportFromBlock
+		if lastBlock, ok := checkpoint.LastBlocks[blockchainID.String()]; ok {
+			fromBlock = lastBlock + 1
+		}
+		toBlock := exportToBlock
+		if !cmd.Flags().Changed("to-block") {
+			toBlock, err = c.BlockNumber(ctx)
+			cobra.CheckErr(err)
+		}
+		chainOut := exportChainOutput{
+			BlockchainID: newBlockchainIDOutput(blockchainID),
+			FromBlock:    fromBlock,
+			ToBlock:      toBlock,
+		}
+		if fromBlock > toBlock {
+			logger.Info("Chain is already exported",
+				zap.String("blockchainID", blockchainID.String()),
+				zap.Uint64("toBlock", toBlock))
+		} else {
+			chainOut.Events, err = exportChain(ctx, c, w, blockchainID, fromBlock, toBlock, exportChunkSize,
+				func(lastBlock uint64) error {
+					checkpoint.LastBlocks[blockchainID.String()] = lastBlock
+					return saveCheckpoint()
+				})
+			cobra.CheckErr(err)
+		}
+		out = append(out, chainOut)
+		c.Close()
+	}
+	cobra.CheckErr(w.close())
+
+	writeOutput(cmd, out)
+	cmd.Println("Export command ran successfully")
+}
+
+// exportChain writes the Teleporter events of a chain between fromBlock and toBlock, inclusive, a chunk
+// of blocks at a time. Once the events of a chunk are flushed, onChunk is called with the last block of
+// the chunk. Returns the number of events written. Logs that fail to decode are skipped.
+func exportChain(
+	ctx context.Context,
+	client exportReader,
+	w *exportWriter,
+	blockchainID ids.ID,
+	fromBlock uint64,
+	toBlock uint64,
+	chunkSize uint64,
+	onChunk func(lastBlock uint64) error,
+) (int, error) {
+	if chunkSize == 0 {
+		chunkSize = defaultLogChunkSize
+	}
+	query := interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress},
+	}
+	count := 0
+	for start := fromBlock; start <= toBlock; {
+		end := start + chunkSize - 1
+		if end > toBlock || end < start {
+			end = toBlock
+		}
+		// Block timestamps and gas used are looked up once per block and transaction.
+		timestamps := make(map[uint64]uint64)
+		gasUsed := make(map[common.Hash]uint64)
+		err := filterLogsInChunks(ctx, client, query, start, end, chunkSize, func(log types.Log) error {
+			name, event, err := parseTeleporterLog(log.Topics, log.Data)
+			if err != nil {
+				logger.Warn("Failed to parse Teleporter log", zap.String("txHash", log.TxHash.Hex()), zap.Error(err))
+				return nil
+			}
+			if _, ok := timestamps[log.BlockNumber]; !ok {
+				header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(log.BlockNumber))
+				if err != nil {
+					return fmt.Errorf("failed to get block %d: %w", log.BlockNumber, err)
+				}
+				timestamps[log.BlockNumber] = header.Time
+			}
+			if _, ok := gasUsed[log.TxHash]; !ok {
+				receipt, err := client.TransactionReceipt(ctx, log.TxHash)
+				if err != nil {
+					return fmt.Errorf("failed to get receipt of transaction %s: %w", log.TxHash.Hex(), err)
+				}
+				gasUsed[log.TxHash] = receipt.GasUsed
+			}
+			count++
+			return w.write(name, exportRecord{
+				BlockchainID:   newBlockchainIDOutput(blockchainID),
+				BlockNumber:    log.BlockNumber,
+				BlockTimestamp: timestamps[log.BlockNumber],
+				TxHash:         log.TxHash.Hex(),
+				LogIndex:       log.Index,
+				GasUsed:        gasUsed[log.TxHash],
+				Event:          newEventFieldsOutput(event),
+			})
+		})
+		if err != nil {
+			return count, err
+		}
+		if err := w.flush(); err != nil {
+			return count, err
+		}
+		if err := onChunk(end); err != nil {
+			return count, err
+		}
+		if end == toBlock {
+			break
+		}
+		start = end + 1
+	}
+	return count, nil
+}
+
+func newExportWriter(dir string, format string) (*exportWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &exportWriter{
+		dir:    dir,
+		format: format,
+		files:  make(map[string]*exportFile),
+	}, nil
+}
+
+// write appends a record to the file of the named event type
+func (w *exportWriter) write(name string, record exportRecord) error {
+	f, err := w.open(name)
+	if err != nil {
+		return err
+	}
+	if f.csv == nil {
+		b, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		_, err = f.file.Write(append(b, '\n'))
+		return err
+	}
+	row, err := record.csvRow(exportEventColumns[name])
+	if err != nil {
+		return err
+	}
+	return f.csv.Write(row)
+}
+
+// open opens the file of the named event type for appending. The header of a new CSV file is
+// written, and that of an existing one must match the columns of the event type.
+func (w *exportWriter) open(name string) (*exportFile, error) {
+	if f, ok := w.files[name]; ok {
+		return f, nil
+	}
+	columns, ok := exportEventColumns[name]
+	if !ok {
+		return nil, fmt.Errorf("no export columns for event %s", name)
+	}
+	path := filepath.Join(w.dir, name+"."+w.format)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	f := &exportFile{file: file}
+	if w.format == csvExportFormat {
+		f.csv = csv.NewWriter(file)
+		header := exportHeader(columns)
+		existing, err := csv.NewReader(file).Read()
+		switch {
+		case errors.Is(err, io.EOF):
+			if err := f.csv.Write(header); err != nil {
+				return nil, err
+			}
+		case err != nil:
+			return nil, fmt.Errorf("failed to read header of %s: %w", path, err)
+		case strings.Join(existing, ",") != strings.Join(header, ","):
+			return nil, fmt.Errorf("%s has different columns than the %s export", path, name)
+		}
+	}
+	w.files[name] = f
+	return f, nil
+}
+
+// truncate rolls the files of the writer's format back to the sizes recorded by a checkpoint,
+// removing the records written after it was saved. Files the checkpoint has no size for were created
+// after it, and are emptied. Nothing is truncated for a checkpoint without file sizes.
+func (w *exportWriter) truncate(sizes map[string]int64) error {
+	if sizes == nil {
+		return nil
+	}
+	for name := range exportEventColumns {
+		fileName := name + "." + w.format
+		path := filepath.Join(w.dir, fileName)
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		size := sizes[fileName]
+		if info.Size() < size {
+			return fmt.Errorf("%s is shorter than recorded by the checkpoint", path)
+		}
+		if info.Size() == size {
+			continue
+		}
+		logger.Info("Removing records written after the checkpoint",
+			zap.String("file", path),
+			zap.Int64("bytes", info.Size()-size))
+		if err := os.Truncate(path, size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileSizes returns the size of each existing file of the writer's format, keyed by file name
+func (w *exportWriter) fileSizes() (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	for name := range exportEventColumns {
+		fileName := name + "." + w.format
+		info, err := os.Stat(filepath.Join(w.dir, fileName))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sizes[fileName] = info.Size()
+	}
+	return sizes, nil
+}
+
+// flush writes the buffered records of every file to disk
+func (w *exportWriter) flush() error {
+	for _, f := range w.files {
+		if f.csv != nil {
+			f.csv.Flush()
+			if err := f.csv.Error(); err != nil {
+				return err
+			}
+		}
+		if err := f.file.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *exportWriter) close() error {
+	err := w.flush()
+	for _, f := range w.files {
+		if closeErr := f.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// exportHeader returns the CSV header of an event type with the given event columns
+func exportHeader(eventColumns []string) []string {
+	header := append([]string{}, exportCommonColumns...)
+	for _, column := range eventColumns {
+		header = append(header, "event."+column)
+	}
+	return header
+}
+
+// csvRow flattens the record into the common columns followed by the given event columns
+func (r exportRecord) csvRow(eventColumns []string) ([]string, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string)
+	if err := flattenExportValue("", doc, fields); err != nil {
+		return nil, err
+	}
+	header := exportHeader(eventColumns)
+	row := make([]string, len(header))
+	for i, column := range header {
+		row[i] = fields[column]
+	}
+	return row, nil
+}
+
+// flattenExportValue adds the scalar values of a decoded JSON value to fields, keyed by their dotted
+// path. Lists and message payloads are JSON encoded into a single value.
+func flattenExportValue(prefix string, v interface{}, fields map[string]string) error {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			if key == "payload" {
+				b, err := json.Marshal(child)
+				if err != nil {
+					return err
+				}
+				fields[path] = string(b)
+				continue
+			}
+			if err := flattenExportValue(path, child, fields); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fields[prefix] = string(b)
+	case nil:
+		fields[prefix] = ""
+	case string:
+		fields[prefix] = value
+	case json.Number:
+		fields[prefix] = value.String()
+	case bool:
+		fields[prefix] = strconv.FormatBool(value)
+	default:
+		return fmt.Errorf("unexpected value %v of %s", v, prefix)
+	}
+	return nil
+}
+
+// readExportCheckpoint reads a checkpoint file. A missing file is an empty checkpoint.
+func readExportCheckpoint(path string) (*exportCheckpoint, error) {
+	checkpoint := &exportCheckpoint{LastBlocks: make(map[string]uint64)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint file %s: %w", path, err)
+	}
+	if checkpoint.LastBlocks == nil {
+		checkpoint.LastBlocks = make(map[string]uint64)
+	}
+	return checkpoint, nil
+}
+
+// resume binds the checkpoint to an export of format from the Teleporter contract at address. A
+// checkpoint saved by an export of another format or contract is an error, since its last blocks
+// and file sizes do not describe the files of this export.
+func (c *exportCheckpoint) resume(format string, address common.Address) error {
+	if c.Format == "" && c.TeleporterAddress == "" {
+		c.Format = format
+		c.TeleporterAddress = address.Hex()
+		return nil
+	}
+	if c.Format != format {
+		return fmt.Errorf("checkpoint is of a %s export, not %s. Use another --dir or --checkpoint", c.Format, format)
+	}
+	if common.HexToAddress(c.TeleporterAddress) != address {
+		return fmt.Errorf(
+			"checkpoint is of an export of Teleporter contract %s, not %s. Use another --dir or --checkpoint",
+			c.TeleporterAddress, address.Hex())
+	}
+	return nil
+}
+
+// save writes the checkpoint to a temporary file and renames it over path, so that an interrupted
+// save leaves the previous checkpoint intact
+func (c *exportCheckpoint) save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringSliceVar(&exportRPCEndpoints, "rpc", []string{}, "RPC endpoints of the chains to export")
+	addChainsFlag(exportCmd)
+	address := exportCmd.Flags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	exportCmd.Flags().StringVar(&exportDir, "dir", "", "Directory to write the export files to")
+	exportCmd.Flags().StringVar(&exportFormat, "format", csvExportFormat, "Export file format, csv or ndjson")
+	exportCmd.Flags().StringVar(&exportCheckpointFile, "checkpoint", "",
+		"Checkpoint file of the last exported blocks. Defaults to checkpoint.json in --dir")
+	exportCmd.Flags().Uint64Var(&exportFromBlock, "from-block", 0,
+		"First block to export chains without a checkpoint from")
+	exportCmd.Flags().Uint64Var(&exportToBlock, "to-block", 0, "Last block to export. Defaults to the latest block")
+	exportCmd.Flags().Uint64Var(&exportChunkSize, "chunk-size", defaultLogChunkSize,
+		"Number of blocks to export between checkpoints, and to query logs for in a single request")
+	err := exportCmd.MarkFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = exportCmd.MarkFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	err = exportCmd.MarkFlagRequired("dir")
+	cobra.CheckErr(err)
+	exportCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Run the persistent pre-run function of the root command if it exists.
+		if err := callPersistentPreRunE(cmd, args); err != nil {
+			return err
+		}
+		if exportFormat != csvExportFormat && exportFormat != ndjsonExportFormat {
+			return fmt.Errorf("invalid export format %s, must be one of %s, %s",
+				exportFormat, csvExportFormat, ndjsonExportFormat)
+		}
+		if err := applyChainsProfile(cmd, "rpc"); err != nil {
+			return err
+		}
+		teleporterAddress = common.HexToAddress(*address)
+		return nil
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/core/types"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "missing flags",
+			args: []string{"export"},
+			err:  fmt.Errorf("required flag(s) \"dir\", \"rpc\", \"teleporter-address\" not set"),
+		},
+		{
+			name: "invalid format",
+			args: []string{"export", "--format", "xlsx"},
+			err:  fmt.Errorf("invalid export format xlsx, must be one of csv, ndjson"),
+		},
+		{
+			name: "help",
+			args: []string{"export", "--help"},
+			err:  nil,
+			out:  "Exports every Teleporter event of each of the --rpc chains to files in --dir",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+	exportFormat = csvExportFormat
+}
+
+// staticExportChain is a chain with fixed logs, blocks with the given timestamps, and transactions
+// that each used a thousand gas per unit of the first byte of their hash
+type staticExportChain struct {
+	staticFilterer
+	times map[uint64]uint64
+}
+
+func (c *staticExportChain) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: number, Time: c.times[number.Uint64()]}, nil
+}
+
+func (c *staticExportChain) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{TxHash: txHash, GasUsed: uint64(txHash[0]) * 1000}, nil
+}
+
+func TestExportChain(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	blockchainID := ids.ID{1}
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		DestinationBlockchainID: ids.ID{2},
+		RequiredGasLimit:        big.NewInt(0),
+		AllowedRelayerAddresses: []common.Address{common.HexToAddress("0xd0")},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{1, 2, 3},
+	}
+	fee := teleportermessenger.TeleporterFeeInfo{FeeTokenAddress: common.HexToAddress("0x01"), Amount: big.NewInt(10)}
+	at := func(log types.Log, block uint64, tx byte, index uint) types.Log {
+		log.BlockNumber = block
+		log.TxHash = common.Hash{tx}
+		log.Index = index
+		return log
+	}
+	messageID := common.BigToHash(big.NewInt(1))
+	send := packTeleporterLog(t, "SendCrossChainMessage", []common.Hash{common.Hash(ids.ID{2}), messageID}, message, fee)
+	addFee := packTeleporterLog(t, "AddFeeAmount", []common.Hash{common.Hash(ids.ID{2}), messageID}, fee)
+	executed := packTeleporterLog(t, "MessageExecuted", []common.Hash{common.Hash(ids.ID{3}), messageID})
+	chain := &staticExportChain{
+		staticFilterer: staticFilterer{logs: []types.Log{
+			at(send, 1, 1, 0),
+			at(addFee, 1, 1, 1),
+			at(executed, 3, 2, 0),
+			// An unknown event of the Teleporter contract
+			at(types.Log{Topics: []common.Hash{{1}}}, 4, 3, 0),
+			at(send, 5, 4, 0),
+		}},
+		times: map[uint64]uint64{1: 100, 3: 104, 5: 108},
+	}
+
+	readCSV := func(t *testing.T, path string) [][]string {
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		records, err := csv.NewReader(f).ReadAll()
+		require.NoError(t, err)
+		return records
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		dir := t.TempDir()
+		w, err := newExportWriter(dir, csvExportFormat)
+		require.NoError(t, err)
+		var checkpoints []uint64
+		onChunk := func(lastBlock uint64) error {
+			checkpoints = append(checkpoints, lastBlock)
+			return nil
+		}
+
+		// Export blocks 1 to 3, then resume from block 4 as after a checkpoint
+		count, err := exportChain(context.Background(), chain, w, blockchainID, 1, 3, 2, onChunk)
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		require.NoError(t, w.close())
+		w, err = newExportWriter(dir, csvExportFormat)
+		require.NoError(t, err)
+		count, err = exportChain(context.Background(), chain, w, blockchainID, 4, 5, 2, onChunk)
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.NoError(t, w.close())
+		require.Equal(t, []uint64{2, 3, 5}, checkpoints)
+
+		records := readCSV(t, filepath.Join(dir, "SendCrossChainMessage.csv"))
+		require.Len(t, records, 3)
+		header := records[0]
+		require.Equal(t, exportHeader(exportEventColumns["SendCrossChainMessage"]), header)
+		row := make(map[string]string)
+		for i, column := range header {
+			row[column] = records[1][i]
+		}
+		require.Equal(t, "1", row["blockNumber"])
+		require.Equal(t, "100", row["blockTimestamp"])
+		require.Equal(t, "1000", row["gasUsed"])
+		require.Equal(t, blockchainID.String(), row["blockchainID.cb58"])
+		require.Equal(t, "10", row["event.feeInfo.amount"])
+		require.Equal(t, "0x010203", row["event.message.message"])
+		require.Equal(t, `["`+common.HexToAddress("0xd0").Hex()+`"]`, row["event.message.allowedRelayerAddresses"])
+		require.Equal(t, "[]", row["event.message.receipts"])
+		require.Equal(t, "5", records[2][2])
+
+		require.Len(t, readCSV(t, filepath.Join(dir, "AddFeeAmount.csv")), 2)
+		require.Len(t, readCSV(t, filepath.Join(dir, "MessageExecuted.csv")), 2)
+		_, err = os.Stat(filepath.Join(dir, "ReceiveCrossChainMessage.csv"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		dir := t.TempDir()
+		w, err := newExportWriter(dir, ndjsonExportFormat)
+		require.NoError(t, err)
+		count, err := exportChain(context.Background(), chain, w, blockchainID, 0, 5, 100,
+			func(uint64) error { return nil })
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+		require.NoError(t, w.close())
+
+		b, err := os.ReadFile(filepath.Join(dir, "SendCrossChainMessage.ndjson"))
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+		require.Len(t, lines, 2)
+		var record struct {
+			BlockNumber    uint64                      `json:"blockNumber"`
+			BlockTimestamp uint64                      `json:"blockTimestamp"`
+			GasUsed        uint64                      `json:"gasUsed"`
+			Event          sendCrossChainMessageOutput `json:"event"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+		require.Equal(t, uint64(5), record.BlockNumber)
+		require.Equal(t, uint64(108), record.BlockTimestamp)
+		require.Equal(t, uint64(4000), record.GasUsed)
+		require.Equal(t, "1", record.Event.MessageID)
+	})
+
+	t.Run("interrupted chunk", func(t *testing.T) {
+		dir := t.TempDir()
+		w, err := newExportWriter(dir, csvExportFormat)
+		require.NoError(t, err)
+		require.NoError(t, w.truncate(nil))
+		var sizes map[string]int64
+		onChunk := func(uint64) error {
+			sizes, err = w.fileSizes()
+			return err
+		}
+		_, err = exportChain(context.Background(), chain, w, blockchainID, 1, 1, 100, onChunk)
+		require.NoError(t, err)
+		sendPath := filepath.Join(dir, "SendCrossChainMessage.csv")
+		committed, err := os.ReadFile(sendPath)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int64{
+			"SendCrossChainMessage.csv": int64(len(committed)),
+			"AddFeeAmount.csv":          sizes["AddFeeAmount.csv"],
+		}, sizes)
+
+		// The next chunk is written but interrupted before its checkpoint
+		_, err = exportChain(context.Background(), chain, w, blockchainID, 2, 5, 100,
+			func(uint64) error { return errors.New("interrupted") })
+		require.Error(t, err)
+		require.NoError(t, w.close())
+		_, err = os.Stat(filepath.Join(dir, "MessageExecuted.csv"))
+		require.NoError(t, err)
+
+		// Continuing from the checkpoint neither duplicates nor keeps the records of the chunk
+		w, err = newExportWriter(dir, csvExportFormat)
+		require.NoError(t, err)
+		require.NoError(t, w.truncate(sizes))
+		_, err = exportChain(context.Background(), chain, w, blockchainID, 2, 5, 100,
+			func(uint64) error { return nil })
+		require.NoError(t, err)
+		require.NoError(t, w.close())
+		require.Len(t, readCSV(t, sendPath), 3)
+		require.Len(t, readCSV(t, filepath.Join(dir, "AddFeeAmount.csv")), 2)
+		require.Len(t, readCSV(t, filepath.Join(dir, "MessageExecuted.csv")), 2)
+
+		// A file cut short after the checkpoint is not silently extended
+		require.NoError(t, os.Truncate(sendPath, 1))
+		w, err = newExportWriter(dir, csvExportFormat)
+		require.NoError(t, err)
+		require.ErrorContains(t, w.truncate(sizes), "is shorter than recorded by the checkpoint")
+	})
+
+	t.Run("mismatched columns", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "MessageExecuted.csv"), []byte("a,b\n"), 0o600))
+		w, err := newExportWriter(dir, csvExportFormat)
+		require.NoError(t, err)
+		_, err = exportChain(context.Background(), chain, w, blockchainID, 3, 3, 100,
+			func(uint64) error { return nil })
+		require.ErrorContains(t, err, "has different columns")
+	})
+}
+
+func TestExportCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	checkpoint, err := readExportCheckpoint(path)
+	require.NoError(t, err)
+	require.Empty(t, checkpoint.LastBlocks)
+
+	// Files are only truncated once a checkpoint records their sizes
+	require.Nil(t, checkpoint.FileSizes)
+
+	checkpoint.LastBlocks[ids.ID{1}.String()] = 10
+	checkpoint.FileSizes = map[string]int64{}
+	require.NoError(t, checkpoint.save(path))
+	checkpoint, err = readExportCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{ids.ID{1}.String(): 10}, checkpoint.LastBlocks)
+	require.Equal(t, map[string]int64{}, checkpoint.FileSizes)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = readExportCheckpoint(path)
+	require.Error(t, err)
+}
+
+func TestExportCheckpointResume(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, defaultCheckpointFileName)
+	address := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	// A CSV export of a chain saves its checkpoint in the directory
+	checkpoint, err := readExportCheckpoint(path)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.resume(csvExportFormat, address))
+	checkpoint.LastBlocks[ids.ID{1}.String()] = 10
+	checkpoint.FileSizes = map[string]int64{"SendCrossChainMessage.csv": 100}
+	require.NoError(t, checkpoint.save(path))
+
+	// An NDJSON export of the same directory would skip the exported blocks and drop the CSV sizes
+	checkpoint, err = readExportCheckpoint(path)
+	require.NoError(t, err)
+	require.ErrorContains(t, checkpoint.resume(ndjsonExportFormat, address), "checkpoint is of a csv export")
+
+	// As would an export of another Teleporter contract
+	checkpoint, err = readExportCheckpoint(path)
+	require.NoError(t, err)
+	require.ErrorContains(t, checkpoint.resume(csvExportFormat, common.HexToAddress("0x01")),
+		"checkpoint is of an export of Teleporter contract")
+
+	// The checkpoint still continues the CSV export
+	checkpoint, err = readExportCheckpoint(path)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.resume(csvExportFormat, address))
+	require.Equal(t, map[string]int64{"SendCrossChainMessage.csv": 100}, checkpoint.FileSizes)
+
+	// Checkpoints saved before the format was recorded are taken to be of the current export
+	require.NoError(t, os.WriteFile(path, []byte(`{"lastBlocks":{}}`), 0o600))
+	checkpoint, err = readExportCheckpoint(path)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.resume(ndjsonExportFormat, address))
+	require.Equal(t, ndjsonExportFormat, checkpoint.Format)
+}
@@ -0,0 +1,170 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	failedFromBlock uint64
+	failedToBlock   uint64
+	failedSince     time.Duration
+	failedChunkSize uint64
+	failedAll       bool
+)
+
+// failedMessageOutput is a message whose execution failed on the destination chain. Message is
+// the ABI encoded TeleporterMessage that retryMessageExecution must be called with.
+type failedMessageOutput struct {
+	OriginBlockchainID blockchainIDOutput `json:"originBlockchainID"`
+	MessageID          string             `json:"messageID"`
+	DestinationAddress string             `json:"destinationAddress"`
+	Retryable          bool               `json:"retryable"`
+	BlockNumber        uint64             `json:"blockNumber"`
+	TxHash             string             `json:"txHash"`
+	Message            string             `json:"message"`
+}
+
+// failedMessageHashReader reads the hashes of the failed messages stored by TeleporterMessenger
+type failedMessageHashReader interface {
+	ReceivedFailedMessageHashes(opts *bind.CallOpts, sourceBlockchainID [32]byte, messageID *big.Int) ([32]byte, error)
+}
+
+var failedCmd = &cobra.Command{
+	Use: "failed --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"[--from-block BLOCK] [--to-block BLOCK] [--since DURATION] [--all]",
+	Short: "Lists the failed message executions of a destination chain that can be retried",
+	Long: `Scans the destination chain for MessageExecutionFailed logs, and checks for
+each failed message whether it can still be retried, that is whether its
+hash is still stored by TeleporterMessenger. The hash is cleared once
+retryMessageExecution succeeds. Only retryable messages are listed, unless
+--all is set. Each entry includes the encoded TeleporterMessage to pass to
+retryMessageExecution.
+
+The range is --from-block, which defaults to the genesis block, to
+--to-block, which defaults to the latest block, or the blocks produced
+within --since of now. Large ranges are queried in chunks of --chunk-size
+blocks.`,
+	Args: cobra.NoArgs,
+	Run:  failedRun,
+}
+
+func failedRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	fromBlock, toBlock, err := getBlockRange(ctx, cmd, client, failedFromBlock, failedToBlock, failedSince)
+	cobra.CheckErr(err)
+	logger.Debug("Scanning failed message executions",
+		zap.Uint64("fromBlock", fromBlock),
+		zap.Uint64("toBlock", toBlock))
+
+	messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, client)
+	cobra.CheckErr(err)
+	failed, err := findFailedMessages(ctx, client, messenger, fromBlock, toBlock, failedChunkSize)
+	cobra.CheckErr(err)
+
+	out := []failedMessageOutput{}
+	for _, message := range failed {
+		if message.Retryable || failedAll {
+			out = append(out, message)
+		}
+	}
+	writeOutput(cmd, out)
+	cmd.Println("Failed command ran successfully")
+}
+
+// findFailedMessages returns the messages whose execution failed between fromBlock and toBlock,
+// in block order.
+func findFailedMessages(
+	ctx context.Context,
+	client logFilterer,
+	reader failedMessageHashReader,
+	fromBlock uint64,
+	toBlock uint64,
+	chunkSize uint64,
+) ([]failedMessageOutput, error) {
+	query := interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress},
+		Topics: [][]common.Hash{{
+			teleporterABI.Events[teleportermessenger.MessageExecutionFailed.String()].ID,
+		}},
+	}
+	var events []*teleportermessenger.TeleporterMessengerMessageExecutionFailed
+	err := filterLogsInChunks(ctx, client, query, fromBlock, toBlock, chunkSize, func(log types.Log) error {
+		_, event, err := parseTeleporterLog(log.Topics, log.Data)
+		if err != nil {
+			logger.Warn("Failed to parse Teleporter log", zap.String("txHash", log.TxHash.Hex()), zap.Error(err))
+			return nil
+		}
+		e, ok := event.(*teleportermessenger.TeleporterMessengerMessageExecutionFailed)
+		if !ok {
+			return nil
+		}
+		e.Raw = log
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	failed := []failedMessageOutput{}
+	for _, e := range events {
+		// The failed message hash is cleared once the execution is successfully retried.
+		hash, err := reader.ReceivedFailedMessageHashes(&bind.CallOpts{Context: ctx}, e.OriginBlockchainID, e.MessageID)
+		if err != nil {
+			return nil, err
+		}
+		message, err := teleportermessenger.PackTeleporterMessage(e.Message)
+		if err != nil {
+			return nil, err
+		}
+		failed = append(failed, failedMessageOutput{
+			OriginBlockchainID: newBlockchainIDOutput(ids.ID(e.OriginBlockchainID)),
+			MessageID:          bigIntString(e.MessageID),
+			DestinationAddress: e.Message.DestinationAddress.Hex(),
+			Retryable:          hash != [32]byte{},
+			BlockNumber:        e.Raw.BlockNumber,
+			TxHash:             e.Raw.TxHash.Hex(),
+			Message:            hexutil.Encode(message),
+		})
+	}
+	return failed, nil
+}
+
+func init() {
+	rootCmd.AddCommand(failedCmd)
+	failedCmd.PersistentFlags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint to connect to the node")
+	address := failedCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addChainFlag(failedCmd)
+	failedCmd.Flags().Uint64Var(&failedFromBlock, "from-block", 0, "First block of the range to scan")
+	failedCmd.Flags().Uint64Var(&failedToBlock, "to-block", 0,
+		"Last block of the range to scan. Defaults to the latest block")
+	failedCmd.Flags().DurationVar(&failedSince, "since", 0,
+		"Scan the blocks produced within this duration of now, i.e. 1h")
+	failedCmd.Flags().Uint64Var(&failedChunkSize, "chunk-size", defaultLogChunkSize,
+		"Maximum number of blocks to query logs for in a single request")
+	failedCmd.Flags().BoolVar(&failedAll, "all", false, "Also list failed messages that were already retried")
+	failedCmd.MarkFlagsMutuallyExclusive("from-block", "since")
+	err := failedCmd.MarkPersistentFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = failedCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	failedCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return rpcPreRunE(cmd, args, address)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailedCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "extra args",
+			args: []string{"failed", "extra"},
+			err:  fmt.Errorf("unknown command \"extra\" for \"teleporter-cli failed\""),
+		},
+		{
+			name: "from block and since",
+			args: []string{"failed", "--rpc", "http://127.0.0.1:9650", "-t", "0x01",
+				"--from-block", "1", "--since", "1h"},
+			err: fmt.Errorf("if any flags in the group [from-block since] are set none of the others can be"),
+		},
+		{
+			name: "help",
+			args: []string{"failed", "--help"},
+			err:  nil,
+			out:  "Scans the destination chain for MessageExecutionFailed logs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+// staticFailedMessageHashes returns the stored hash of the failed messages, keyed by message ID
+type staticFailedMessageHashes map[string][32]byte
+
+func (h staticFailedMessageHashes) ReceivedFailedMessageHashes(
+	_ *bind.CallOpts,
+	_ [32]byte,
+	messageID *big.Int,
+) ([32]byte, error) {
+	return h[messageID.String()], nil
+}
+
+func TestFindFailedMessages(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	origin := ids.ID{1}
+	destinationAddress := common.HexToAddress("0x02")
+	message := func(id int64) teleportermessenger.TeleporterMessage {
+		return teleportermessenger.TeleporterMessage{
+			MessageID:               big.NewInt(id),
+			DestinationBlockchainID: ids.ID{2},
+			DestinationAddress:      destinationAddress,
+			RequiredGasLimit:        big.NewInt(100_000),
+			AllowedRelayerAddresses: []common.Address{},
+			Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+			Message:                 []byte{0x01},
+		}
+	}
+	failedLog := func(id int64, block uint64) types.Log {
+		log := packTeleporterLog(t, "MessageExecutionFailed",
+			[]common.Hash{common.Hash(origin), common.BigToHash(big.NewInt(id))}, message(id))
+		log.BlockNumber = block
+		log.TxHash = common.BigToHash(big.NewInt(int64(block)))
+		return log
+	}
+	filterer := &staticFilterer{logs: []types.Log{
+		failedLog(1, 1),
+		failedLog(2, 2),
+		// Other Teleporter logs are skipped
+		packTeleporterLog(t, "MessageExecuted",
+			[]common.Hash{common.Hash(origin), common.BigToHash(big.NewInt(3))}),
+		// Logs after the range are skipped
+		failedLog(4, 10),
+	}}
+	// Message 2 was successfully retried, so its hash was cleared
+	hashes := staticFailedMessageHashes{"1": {1}, "4": {4}}
+
+	failed, err := findFailedMessages(context.Background(), filterer, hashes, 0, 5, 2)
+	require.NoError(t, err)
+
+	encoded := func(id int64) string {
+		b, err := teleportermessenger.PackTeleporterMessage(message(id))
+		require.NoError(t, err)
+		return hexutil.Encode(b)
+	}
+	require.Equal(t, []failedMessageOutput{
+		{
+			OriginBlockchainID: newBlockchainIDOutput(origin),
+			MessageID:          "1",
+			DestinationAddress: destinationAddress.Hex(),
+			Retryable:          true,
+			BlockNumber:        1,
+			TxHash:             common.BigToHash(big.NewInt(1)).Hex(),
+			Message:            encoded(1),
+		},
+		{
+			OriginBlockchainID: newBlockchainIDOutput(origin),
+			MessageID:          "2",
+			DestinationAddress: destinationAddress.Hex(),
+			Retryable:          false,
+			BlockNumber:        2,
+			TxHash:             common.BigToHash(big.NewInt(2)).Hex(),
+			Message:            encoded(2),
+		},
+	}, failed)
+}
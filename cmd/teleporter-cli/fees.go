@@ -0,0 +1,239 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	feesTokenAddress string
+	feesAmount       string
+)
+
+// messageFeesOutput is the fee of a sent message, and the additions made to it
+type messageFeesOutput struct {
+	DestinationBlockchainID blockchainIDOutput `json:"destinationBlockchainID"`
+	MessageID               string             `json:"messageID"`
+	Pending                 bool               `json:"pending"`
+	FeeInfo                 feeInfoOutput      `json:"feeInfo"`
+	AddFeeAmounts           []eventOutput      `json:"addFeeAmounts"`
+}
+
+var feesCmd = &cobra.Command{
+	Use:   "fees",
+	Short: "Inspects and tops up the relayer fees of sent Teleporter messages",
+	Long: `Inspects the relayer fee of a Teleporter message sent from the --rpc chain,
+and adds to the fee of a message that relayers are not delivering. The fee
+info of a message is only kept on the source chain until the receipt for the
+message is received, after which no fee can be added to it.`,
+}
+
+var feesShowCmd = &cobra.Command{
+	Use:   "show --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS DESTINATION_BLOCKCHAIN_ID MESSAGE_ID",
+	Short: "Shows the fee info of a sent message",
+	Long: `Shows the current fee info of a sent message from getFeeInfo, and every
+AddFeeAmount log of the message in the most recent --lookback-blocks blocks.
+The destination blockchain ID may be given in cb58, as 0x prefixed hex, or as
+the name of a chain in the config file.`,
+	Args: cobra.ExactArgs(2),
+	Run:  feesShowRun,
+}
+
+var feesAddCmd = &cobra.Command{
+	Use: "add --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"[--private-key KEY | --keystore FILE | --external-signer URL] --amount AMOUNT " +
+		"DESTINATION_BLOCKCHAIN_ID MESSAGE_ID",
+	Short: "Adds to the fee of a sent message",
+	Long: `Approves the Teleporter contract to spend the fee token if needed, then calls
+addFeeAmount to add --amount to the fee of a sent message. The fee token must
+be the one the message was sent with, and defaults to it. Prints the
+AddFeeAmount log with the updated fee info.`,
+	Args: cobra.ExactArgs(2),
+	Run:  feesAddRun,
+}
+
+func feesShowRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	destinationBlockchainID, messageID, err := parseMessageIDArgs(args)
+	cobra.CheckErr(err)
+	messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, client)
+	cobra.CheckErr(err)
+
+	feeInfo, pending, err := getMessageFeeInfo(ctx, messenger, destinationBlockchainID, messageID)
+	cobra.CheckErr(err)
+	out := messageFeesOutput{
+		DestinationBlockchainID: newBlockchainIDOutput(destinationBlockchainID),
+		MessageID:               messageID.String(),
+		Pending:                 pending,
+		FeeInfo:                 newFeeInfoOutput(feeInfo),
+		AddFeeAmounts:           []eventOutput{},
+	}
+
+	out.AddFeeAmounts, err = getAddFeeAmounts(ctx, client, destinationBlockchainID, messageID, lookbackBlocks)
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, out)
+	cmd.Println("Fees show command ran successfully")
+}
+
+func feesAddRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	signer, err := newSigner(cmd)
+	cobra.CheckErr(err)
+	destinationBlockchainID, messageID, err := parseMessageIDArgs(args)
+	cobra.CheckErr(err)
+	amount, ok := new(big.Int).SetString(feesAmount, 0)
+	if !ok || amount.Sign() <= 0 {
+		cobra.CheckErr(fmt.Errorf("invalid fee amount %s", feesAmount))
+	}
+	messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, client)
+	cobra.CheckErr(err)
+
+	feeInfo, err := getAddableFeeInfo(ctx, messenger, destinationBlockchainID, messageID, feesTokenAddress)
+	cobra.CheckErr(err)
+	feeTokenAddress := feeInfo.FeeTokenAddress
+	logger.Debug("Adding fee amount",
+		zap.String("messageID", messageID.String()),
+		zap.String("feeTokenAddress", feeTokenAddress.Hex()),
+		zap.String("currentAmount", feeInfo.Amount.String()),
+		zap.String("additionalAmount", amount.String()))
+
+	err = approveERC20(ctx, client, signer, feeTokenAddress, teleporterAddress, amount)
+	cobra.CheckErr(err)
+
+	data, err := teleportermessenger.PackAddFeeAmount(destinationBlockchainID, messageID, feeTokenAddress, amount)
+	cobra.CheckErr(err)
+	receipt, err := sendContractTransaction(ctx, client, signer, teleporterAddress, data)
+	cobra.CheckErr(err)
+
+	event, err := getEventFromLogs(receipt.Logs, messenger.ParseAddFeeAmount)
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, newEventOutput(teleportermessenger.AddFeeAmount.String(), event, &event.Raw))
+	cmd.Println("Fees add command ran successfully")
+}
+
+// getMessageFeeInfo returns the current fee info of a sent message, and whether the message is
+// still pending. The message hash and fee info are only stored until the receipt for the message
+// is received.
+func getMessageFeeInfo(
+	ctx context.Context,
+	messenger sourceMessageReader,
+	destinationBlockchainID ids.ID,
+	messageID *big.Int,
+) (teleportermessenger.TeleporterFeeInfo, bool, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	messageHash, err := messenger.GetMessageHash(opts, destinationBlockchainID, messageID)
+	if err != nil {
+		return teleportermessenger.TeleporterFeeInfo{}, false, err
+	}
+	feeTokenAddress, amount, err := messenger.GetFeeInfo(opts, destinationBlockchainID, messageID)
+	if err != nil {
+		return teleportermessenger.TeleporterFeeInfo{}, false, err
+	}
+	feeInfo := teleportermessenger.TeleporterFeeInfo{
+		FeeTokenAddress: feeTokenAddress,
+		Amount:          amount,
+	}
+	return feeInfo, messageHash != [32]byte{}, nil
+}
+
+// getAddableFeeInfo returns the current fee info of a sent message that a fee can be added to.
+// feeToken, if set, must be the fee token the message was sent with.
+func getAddableFeeInfo(
+	ctx context.Context,
+	messenger sourceMessageReader,
+	destinationBlockchainID ids.ID,
+	messageID *big.Int,
+	feeToken string,
+) (teleportermessenger.TeleporterFeeInfo, error) {
+	feeInfo, pending, err := getMessageFeeInfo(ctx, messenger, destinationBlockchainID, messageID)
+	if err != nil {
+		return teleportermessenger.TeleporterFeeInfo{}, err
+	}
+	if !pending {
+		return teleportermessenger.TeleporterFeeInfo{}, fmt.Errorf(
+			"message %s to %s was not sent, or its receipt was already received", messageID, destinationBlockchainID)
+	}
+	// Only a single fee token may be used to incentivize the delivery of a given message.
+	if feeInfo.FeeTokenAddress == (common.Address{}) {
+		return teleportermessenger.TeleporterFeeInfo{}, fmt.Errorf(
+			"message %s to %s was sent without a fee token, so no fee can be added", messageID, destinationBlockchainID)
+	}
+	if feeToken != "" && common.HexToAddress(feeToken) != feeInfo.FeeTokenAddress {
+		return teleportermessenger.TeleporterFeeInfo{}, fmt.Errorf(
+			"fee token %s does not match the fee token %s the message was sent with", feeToken, feeInfo.FeeTokenAddress)
+	}
+	return feeInfo, nil
+}
+
+// getAddFeeAmounts returns the AddFeeAmount logs of a sent message in the most recent lookback blocks
+func getAddFeeAmounts(
+	ctx context.Context,
+	client latestLogReader,
+	destinationBlockchainID ids.ID,
+	messageID *big.Int,
+	lookback uint64,
+) ([]eventOutput, error) {
+	query := interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress},
+		Topics: [][]common.Hash{
+			{teleporterABI.Events[teleportermessenger.AddFeeAmount.String()].ID},
+			{common.Hash(destinationBlockchainID)},
+			{common.BigToHash(messageID)},
+		},
+	}
+	addFeeAmounts := []eventOutput{}
+	err := filterRecentLogs(ctx, client, query, lookback, func(log types.Log) error {
+		name, event, err := parseTeleporterLog(log.Topics, log.Data)
+		if err != nil {
+			logger.Warn("Failed to parse Teleporter log", zap.String("txHash", log.TxHash.Hex()), zap.Error(err))
+			return nil
+		}
+		addFeeAmounts = append(addFeeAmounts, newEventOutput(name, event, &log))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addFeeAmounts, nil
+}
+
+func init() {
+	rootCmd.AddCommand(feesCmd)
+	feesCmd.AddCommand(feesShowCmd)
+	feesCmd.AddCommand(feesAddCmd)
+	feesCmd.PersistentFlags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint of the source chain")
+	address := feesCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addChainFlag(feesCmd)
+	feesShowCmd.Flags().Uint64Var(&lookbackBlocks, "lookback-blocks", defaultLookbackBlocks,
+		"Number of recent blocks to search for AddFeeAmount logs")
+	addSignerFlags(feesAddCmd.Flags(), "to sign the transaction")
+	feesAddCmd.Flags().StringVar(&feesAmount, "amount", "", "Amount of the fee token to add to the fee")
+	feesAddCmd.Flags().StringVar(&feesTokenAddress, "fee-token", "",
+		"Address of the ERC20 fee token. Defaults to the fee token the message was sent with")
+	err := feesCmd.MarkPersistentFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = feesCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	err = feesAddCmd.MarkFlagRequired("amount")
+	cobra.CheckErr(err)
+	feesCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return rpcPreRunE(cmd, args, address)
+	}
+}
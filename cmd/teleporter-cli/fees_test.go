@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/core/types"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeesCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "show no args",
+			args: []string{"fees", "show"},
+			err:  fmt.Errorf("accepts 2 arg(s), received 0"),
+		},
+		{
+			name: "add too few args",
+			args: []string{"fees", "add", "extra"},
+			err:  fmt.Errorf("accepts 2 arg(s), received 1"),
+		},
+		{
+			name: "show help",
+			args: []string{"fees", "show", "--help"},
+			err:  nil,
+			out:  "Shows the current fee info of a sent message from getFeeInfo",
+		},
+		{
+			name: "add help",
+			args: []string{"fees", "add", "--help"},
+			err:  nil,
+			out:  "calls\naddFeeAmount to add --amount to the fee of a sent message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestGetAddableFeeInfo(t *testing.T) {
+	destination := ids.ID{2}
+	messageID := big.NewInt(1)
+	feeToken := common.HexToAddress("0x01")
+
+	var tests = []struct {
+		name     string
+		hash     [32]byte
+		feeToken common.Address
+		flag     string
+		err      string
+	}{
+		{
+			name:     "pending",
+			hash:     [32]byte{1},
+			feeToken: feeToken,
+		},
+		{
+			name:     "matching fee token flag",
+			hash:     [32]byte{1},
+			feeToken: feeToken,
+			flag:     feeToken.Hex(),
+		},
+		{
+			name:     "other fee token flag",
+			hash:     [32]byte{1},
+			feeToken: feeToken,
+			flag:     "0x02",
+			err:      "does not match the fee token",
+		},
+		{
+			name: "sent without fee token",
+			hash: [32]byte{1},
+			err:  "was sent without a fee token",
+		},
+		{
+			name:     "receipt received",
+			feeToken: feeToken,
+			err:      "or its receipt was already received",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messenger := &staticMessenger{
+				destinationBlockchainID: destination,
+				messageID:               messageID,
+				messageHash:             tt.hash,
+				feeInfo: teleportermessenger.TeleporterFeeInfo{
+					FeeTokenAddress: tt.feeToken,
+					Amount:          big.NewInt(10),
+				},
+			}
+			feeInfo, err := getAddableFeeInfo(context.Background(), messenger, destination, messageID, tt.flag)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, feeToken, feeInfo.FeeTokenAddress)
+			require.Equal(t, big.NewInt(10), feeInfo.Amount)
+		})
+	}
+
+	// The fee info is read for the route of the message
+	_, err := getAddableFeeInfo(context.Background(), &staticMessenger{messageID: messageID}, destination, messageID, "")
+	require.ErrorIs(t, err, errUnexpectedMessage)
+}
+
+func TestGetAddFeeAmounts(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	destination := ids.ID{2}
+	messageID := big.NewInt(1)
+	addFee := func(amount int64, block uint64) types.Log {
+		log := packTeleporterLog(t, "AddFeeAmount",
+			[]common.Hash{common.Hash(destination), common.BigToHash(messageID)},
+			teleportermessenger.TeleporterFeeInfo{FeeTokenAddress: common.HexToAddress("0x01"), Amount: big.NewInt(amount)})
+		log.BlockNumber = block
+		log.Index = uint(amount)
+		return log
+	}
+	// Blocks 0 to 10, of which the lookback covers 5 to 10
+	chain := newStaticChain(0, 1, 11, addFee(1, 2), addFee(2, 6), addFee(3, 10))
+
+	addFeeAmounts, err := getAddFeeAmounts(context.Background(), chain, destination, messageID, 5)
+	require.NoError(t, err)
+	require.Len(t, addFeeAmounts, 2)
+	for i, block := range []uint64{6, 10} {
+		require.Equal(t, "AddFeeAmount", addFeeAmounts[i].Name)
+		require.Equal(t, block, *addFeeAmounts[i].BlockNumber)
+	}
+	require.Equal(t, uint(3), *addFeeAmounts[1].LogIndex)
+
+	// A lookback of zero only covers the latest block
+	addFeeAmounts, err = getAddFeeAmounts(context.Background(), chain, destination, messageID, 0)
+	require.NoError(t, err)
+	require.Len(t, addFeeAmounts, 1)
+
+	// A message without additions has an empty list rather than none
+	addFeeAmounts, err = getAddFeeAmounts(context.Background(), newStaticChain(0, 1, 11), destination, messageID, 5)
+	require.NoError(t, err)
+	require.NotNil(t, addFeeAmounts)
+	require.Empty(t, addFeeAmounts)
+}
@@ -0,0 +1,274 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var hashTxHash string
+
+// messageFieldDifferenceOutput is a field of a TeleporterMessage whose local value differs from
+// the value of the original message
+type messageFieldDifferenceOutput struct {
+	Field    string `json:"field"`
+	Original string `json:"original"`
+	Local    string `json:"local"`
+}
+
+// hashOutput compares the hash of a local message with the hashes stored by TeleporterMessenger.
+// The stored hashes are only set while the message is pending on the source chain, or while its
+// failed execution can be retried on the destination chain.
+type hashOutput struct {
+	MessageID         string                         `json:"messageID"`
+	MessageHash       string                         `json:"messageHash"`
+	SourceMessageHash string                         `json:"sourceMessageHash,omitempty"`
+	FailedMessageHash string                         `json:"failedMessageHash,omitempty"`
+	OriginalHash      string                         `json:"originalHash,omitempty"`
+	SendTxHash        string                         `json:"sendTxHash,omitempty"`
+	Matches           bool                           `json:"matches"`
+	Differences       []messageFieldDifferenceOutput `json:"differences"`
+}
+
+var hashCmd = &cobra.Command{
+	Use: "hash --source-rpc RPC_URL --dest-rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"[--tx TRANSACTION_HASH] INPUT_FILE",
+	Short: "Checks a TeleporterMessage against the hashes stored by TeleporterMessenger",
+	Long: `Given a file containing a TeleporterMessage in JSON or YAML, using the same schema
+as the output of the message command, this command computes the hash of the
+message and compares it against the message hash stored on the source chain,
+which is cleared once the receipt of the message is received, and the failed
+message hash stored on the destination chain, which is cleared once a failed
+execution is successfully retried. Use - to read the message from stdin.
+
+Retries of a message with a different hash are rejected with "invalid message
+hash". When the hashes differ, the fields of the message that differ from the
+original SendCrossChainMessage log are reported. The original log is loaded
+from --tx, or by searching the most recent --lookback-blocks blocks of the
+source chain for the message ID of the local message.`,
+	Args: cobra.ExactArgs(1),
+	Run:  hashRun,
+}
+
+func hashRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	b, err := readInputFile(cmd, args[0])
+	cobra.CheckErr(err)
+	message, err := parseMessageInput(b)
+	cobra.CheckErr(err)
+	messageHash, err := teleportermessenger.HashTeleporterMessage(message)
+	cobra.CheckErr(err)
+
+	sourceBlockchainID, err := getBlockchainID(ctx, sourceClient)
+	cobra.CheckErr(err)
+	destinationBlockchainID, err := getBlockchainID(ctx, destClient)
+	cobra.CheckErr(err)
+	sourceMessenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, sourceClient)
+	cobra.CheckErr(err)
+	destMessenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, destClient)
+	cobra.CheckErr(err)
+
+	sendEvent, err := getOriginalSendEvent(ctx, sourceMessenger, destinationBlockchainID, message.MessageID)
+	cobra.CheckErr(err)
+	// The message ID of the original message takes precedence, since the local one may be altered.
+	messageID := message.MessageID
+	if sendEvent != nil {
+		messageID = sendEvent.MessageID
+	}
+	logger.Debug("Checking message hash",
+		zap.String("messageID", messageID.String()),
+		zap.String("sourceBlockchainID", sourceBlockchainID.String()),
+		zap.String("destinationBlockchainID", destinationBlockchainID.String()))
+
+	sourceMessageHash, err := sourceMessenger.GetMessageHash(
+		&bind.CallOpts{Context: ctx}, destinationBlockchainID, messageID,
+	)
+	cobra.CheckErr(err)
+	failedMessageHash, err := destMessenger.ReceivedFailedMessageHashes(
+		&bind.CallOpts{Context: ctx}, sourceBlockchainID, messageID,
+	)
+	cobra.CheckErr(err)
+
+	var original *teleportermessenger.TeleporterMessage
+	if sendEvent != nil {
+		original = &sendEvent.Message
+	}
+	out, err := compareMessageHashes(message, original, sourceMessageHash, failedMessageHash)
+	cobra.CheckErr(err)
+	out.MessageID = messageID.String()
+	out.MessageHash = messageHash.Hex()
+	if sendEvent != nil {
+		out.SendTxHash = sendEvent.Raw.TxHash.Hex()
+	}
+
+	writeOutput(cmd, out)
+	cmd.Println("Hash command ran successfully")
+}
+
+// getOriginalSendEvent loads the SendCrossChainMessage log of a message from --tx, or from the most
+// recent --lookback-blocks blocks of the source chain. It returns nil if no log is found by message ID.
+func getOriginalSendEvent(
+	ctx context.Context,
+	sourceMessenger *teleportermessenger.TeleporterMessenger,
+	destinationBlockchainID ids.ID,
+	messageID *big.Int,
+) (*teleportermessenger.TeleporterMessengerSendCrossChainMessage, error) {
+	if hashTxHash != "" {
+		receipt, err := sourceClient.TransactionReceipt(ctx, common.HexToHash(hashTxHash))
+		if err != nil {
+			return nil, err
+		}
+		return getSendEventToDestination(receipt.Logs, sourceMessenger, destinationBlockchainID)
+	}
+
+	startBlock, err := getLookbackStartBlock(ctx, sourceClient, lookbackBlocks)
+	if err != nil {
+		return nil, err
+	}
+	it, err := sourceMessenger.FilterSendCrossChainMessage(
+		&bind.FilterOpts{Start: startBlock, Context: ctx},
+		[][32]byte{destinationBlockchainID},
+		[]*big.Int{messageID},
+	)
+	if err != nil {
+		return nil, err
+	}
+	var sendEvent *teleportermessenger.TeleporterMessengerSendCrossChainMessage
+	for it.Next() {
+		sendEvent = it.Event
+	}
+	return sendEvent, it.Error()
+}
+
+// compareMessageHashes compares the hash of a local message with the hashes stored on the source
+// and destination chains, and with the original message if it is known. The local message matches
+// if its hash equals every stored hash, and the differing fields are listed if it does not.
+func compareMessageHashes(
+	local teleportermessenger.TeleporterMessage,
+	original *teleportermessenger.TeleporterMessage,
+	sourceMessageHash [32]byte,
+	failedMessageHash [32]byte,
+) (hashOutput, error) {
+	out := hashOutput{Differences: []messageFieldDifferenceOutput{}}
+	localHash, err := teleportermessenger.HashTeleporterMessage(local)
+	if err != nil {
+		return out, err
+	}
+
+	var expected []common.Hash
+	if sourceMessageHash != [32]byte{} {
+		out.SourceMessageHash = hexutil.Encode(sourceMessageHash[:])
+		expected = append(expected, sourceMessageHash)
+	}
+	if failedMessageHash != [32]byte{} {
+		out.FailedMessageHash = hexutil.Encode(failedMessageHash[:])
+		expected = append(expected, failedMessageHash)
+	}
+	if original != nil {
+		originalHash, err := teleportermessenger.HashTeleporterMessage(*original)
+		if err != nil {
+			return out, err
+		}
+		out.OriginalHash = originalHash.Hex()
+		expected = append(expected, originalHash)
+	}
+	if len(expected) == 0 {
+		return out, fmt.Errorf("no stored hash or SendCrossChainMessage log found for message %s", local.MessageID)
+	}
+
+	out.Matches = true
+	for _, hash := range expected {
+		out.Matches = out.Matches && hash == localHash
+	}
+	if !out.Matches && original != nil {
+		out.Differences = diffTeleporterMessages(*original, local)
+	}
+	return out, nil
+}
+
+// diffTeleporterMessages returns the fields of the local message that differ from the original
+func diffTeleporterMessages(original, local teleportermessenger.TeleporterMessage) []messageFieldDifferenceOutput {
+	fields := []struct {
+		name     string
+		original string
+		local    string
+	}{
+		{"messageID", bigIntString(original.MessageID), bigIntString(local.MessageID)},
+		{"senderAddress", original.SenderAddress.Hex(), local.SenderAddress.Hex()},
+		{
+			"destinationBlockchainID",
+			ids.ID(original.DestinationBlockchainID).String(),
+			ids.ID(local.DestinationBlockchainID).String(),
+		},
+		{"destinationAddress", original.DestinationAddress.Hex(), local.DestinationAddress.Hex()},
+		{"requiredGasLimit", bigIntString(original.RequiredGasLimit), bigIntString(local.RequiredGasLimit)},
+		{
+			"allowedRelayerAddresses",
+			addressesString(original.AllowedRelayerAddresses),
+			addressesString(local.AllowedRelayerAddresses),
+		},
+		{"receipts", receiptsString(original.Receipts), receiptsString(local.Receipts)},
+		{"message", hexutil.Encode(original.Message), hexutil.Encode(local.Message)},
+	}
+	differences := []messageFieldDifferenceOutput{}
+	for _, field := range fields {
+		if field.original != field.local {
+			differences = append(differences, messageFieldDifferenceOutput{
+				Field:    field.name,
+				Original: field.original,
+				Local:    field.local,
+			})
+		}
+	}
+	return differences
+}
+
+func addressesString(addresses []common.Address) string {
+	s := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		s = append(s, address.Hex())
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+func receiptsString(receipts []teleportermessenger.TeleporterMessageReceipt) string {
+	s := make([]string, 0, len(receipts))
+	for _, receipt := range receipts {
+		s = append(s, fmt.Sprintf("%s:%s", bigIntString(receipt.ReceivedMessageID), receipt.RelayerRewardAddress.Hex()))
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+func init() {
+	rootCmd.AddCommand(hashCmd)
+	hashCmd.PersistentFlags().StringVar(&sourceRPCEndpoint, "source-rpc", "", "RPC endpoint of the source chain")
+	hashCmd.PersistentFlags().StringVar(&destRPCEndpoint, "dest-rpc", "", "RPC endpoint of the destination chain")
+	address := hashCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addFromToFlags(hashCmd)
+	hashCmd.Flags().StringVar(&hashTxHash, "tx", "",
+		"Hash of the source chain transaction that sent the original message")
+	hashCmd.Flags().Uint64Var(&lookbackBlocks, "lookback-blocks", defaultLookbackBlocks,
+		"Number of recent blocks to search for the original message log")
+	err := hashCmd.MarkPersistentFlagRequired("source-rpc")
+	cobra.CheckErr(err)
+	err = hashCmd.MarkPersistentFlagRequired("dest-rpc")
+	cobra.CheckErr(err)
+	err = hashCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	hashCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return sourceDestPreRunE(cmd, args, address)
+	}
+}
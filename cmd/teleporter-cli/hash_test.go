@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"hash"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"hash", "--help"},
+			err:  nil,
+			out:  "computes the hash of the",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestCompareMessageHashes(t *testing.T) {
+	original := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		SenderAddress:           common.HexToAddress("0x01"),
+		DestinationBlockchainID: ids.ID{1},
+		DestinationAddress:      common.HexToAddress("0x02"),
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{common.HexToAddress("0x03")},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{0x01},
+	}
+	originalHash, err := teleportermessenger.HashTeleporterMessage(original)
+	require.NoError(t, err)
+
+	altered := original
+	altered.RequiredGasLimit = big.NewInt(200_000)
+	altered.AllowedRelayerAddresses = []common.Address{}
+	altered.Message = []byte{0x02}
+
+	var tests = []struct {
+		name              string
+		local             teleportermessenger.TeleporterMessage
+		original          *teleportermessenger.TeleporterMessage
+		sourceMessageHash [32]byte
+		failedMessageHash [32]byte
+		matches           bool
+		differences       []messageFieldDifferenceOutput
+		err               bool
+	}{
+		{
+			name:              "matches failed message hash",
+			local:             original,
+			failedMessageHash: originalHash,
+			matches:           true,
+		},
+		{
+			name:              "matches stored hashes and original",
+			local:             original,
+			original:          &original,
+			sourceMessageHash: originalHash,
+			failedMessageHash: originalHash,
+			matches:           true,
+		},
+		{
+			name:              "altered without original",
+			local:             altered,
+			failedMessageHash: originalHash,
+			matches:           false,
+		},
+		{
+			name:              "altered",
+			local:             altered,
+			original:          &original,
+			failedMessageHash: originalHash,
+			matches:           false,
+			differences: []messageFieldDifferenceOutput{
+				{Field: "requiredGasLimit", Original: "100000", Local: "200000"},
+				{
+					Field:    "allowedRelayerAddresses",
+					Original: "[" + common.HexToAddress("0x03").Hex() + "]",
+					Local:    "[]",
+				},
+				{Field: "message", Original: "0x01", Local: "0x02"},
+			},
+		},
+		{
+			name:  "nothing to compare",
+			local: original,
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := compareMessageHashes(tt.local, tt.original, tt.sourceMessageHash, tt.failedMessageHash)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.matches, out.Matches)
+			if tt.differences == nil {
+				tt.differences = []messageFieldDifferenceOutput{}
+			}
+			require.Equal(t, tt.differences, out.Differences)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// readInputFile reads the file at path, or the command's standard input if path is -
+func readInputFile(cmd *cobra.Command, path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(cmd.InOrStdin())
+	}
+	return os.ReadFile(path)
+}
+
+// unmarshalInput parses JSON or YAML input into v. YAML is converted to JSON first,
+// so that both formats share the JSON schema of v.
+func unmarshalInput(b []byte, v interface{}) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	var value interface{}
+	if len(doc.Content) > 0 {
+		var err error
+		value, err = yamlNodeValue(doc.Content[0])
+		if err != nil {
+			return err
+		}
+	}
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonBytes, v)
+}
+
+// yamlNodeValue converts a YAML node to a value that marshals to the equivalent JSON. Integers
+// written in hex, octal or binary are kept as the string they were written as, since unquoted
+// 0x prefixed byte arrays, addresses and hashes are also YAML integers.
+func yamlNodeValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlNodeValue(node.Alias)
+	case yaml.MappingNode:
+		out := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := yamlNodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			out[node.Content[i].Value] = value
+		}
+		return out, nil
+	case yaml.SequenceNode:
+		out := make([]interface{}, 0, len(node.Content))
+		for _, child := range node.Content {
+			value, err := yamlNodeValue(child)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, value)
+		}
+		return out, nil
+	}
+
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var value bool
+		err := node.Decode(&value)
+		return value, err
+	case "!!int", "!!float":
+		// Decimal numbers are JSON numbers as written, so that large integers keep their precision.
+		if json.Valid([]byte(node.Value)) {
+			return json.Number(node.Value), nil
+		}
+		// Decimal integers that are not valid JSON numbers, i.e. with leading zeros
+		if i, ok := new(big.Int).SetString(node.Value, 10); ok {
+			return json.Number(i.String()), nil
+		}
+		return node.Value, nil
+	default:
+		return node.Value, nil
+	}
+}
+
+// bigIntInput is a big integer read from a JSON number, or a decimal or 0x prefixed hex string
+type bigIntInput struct {
+	*big.Int
+}
+
+func (b *bigIntInput) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		str = string(data)
+	}
+	i, ok := new(big.Int).SetString(str, 0)
+	if !ok {
+		return fmt.Errorf("invalid integer %s", str)
+	}
+	b.Int = i
+	return nil
+}
+
+// blockchainIDInput is a blockchain ID read either from a cb58 or hex string, or from
+// the {"cb58", "hex"} object written by the CLI
+type blockchainIDInput struct {
+	ids.ID
+}
+
+func (b *blockchainIDInput) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		var out blockchainIDOutput
+		if err := json.Unmarshal(data, &out); err != nil {
+			return err
+		}
+		str = out.Hex
+		if str == "" {
+			str = out.CB58
+		}
+	}
+	id, err := parseBlockchainID(str)
+	if err != nil {
+		return err
+	}
+	b.ID = id
+	return nil
+}
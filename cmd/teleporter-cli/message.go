@@ -8,7 +8,6 @@ import (
 
 	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
 	"github.com/spf13/cobra"
-	"go.uber.org/zap"
 )
 
 var messageCmd = &cobra.Command{
@@ -24,7 +23,7 @@ the bytes into a TeleporterMessage struct and print the struct fields.`,
 
 		msg, err := teleportermessenger.UnpackTeleporterMessage(b)
 		cobra.CheckErr(err)
-		logger.Info("Teleporter Message unpacked", zap.Any("message", msg))
+		writeOutput(cmd, newMessageOutput(*msg))
 		cmd.Println("Message command ran successfully")
 	},
 }
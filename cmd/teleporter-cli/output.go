@@ -0,0 +1,466 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/subnet-evm/core/types"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	textFormat  = "text"
+	jsonFormat  = "json"
+	yamlFormat  = "yaml"
+	tableFormat = "table"
+)
+
+var (
+	outputFormat string
+	// outputLock serializes writes from commands that produce results concurrently
+	outputLock sync.Mutex
+)
+
+//
+// Output schemas. These are stable across releases: blockchain IDs are written in both
+// cb58 and hex, big integers as decimal strings, and byte arrays as 0x prefixed hex.
+//
+
+type blockchainIDOutput struct {
+	CB58 string `json:"cb58"`
+	Hex  string `json:"hex"`
+}
+
+type feeInfoOutput struct {
+	FeeTokenAddress string `json:"feeTokenAddress"`
+	Amount          string `json:"amount"`
+}
+
+type receiptOutput struct {
+	ReceivedMessageID    string `json:"receivedMessageID"`
+	RelayerRewardAddress string `json:"relayerRewardAddress"`
+}
+
+type messageOutput struct {
+	MessageID               string             `json:"messageID"`
+	SenderAddress           string             `json:"senderAddress"`
+	DestinationBlockchainID blockchainIDOutput `json:"destinationBlockchainID"`
+	DestinationAddress      string             `json:"destinationAddress"`
+	RequiredGasLimit        string             `json:"requiredGasLimit"`
+	AllowedRelayerAddresses []string           `json:"allowedRelayerAddresses"`
+	Receipts                []receiptOutput    `json:"receipts"`
+	Message                 string             `json:"message"`
+	// Payload is the decoded message, if a payload decoder is registered for the destination address
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+type sendCrossChainMessageOutput struct {
+	DestinationBlockchainID blockchainIDOutput `json:"destinationBlockchainID"`
+	MessageID               string             `json:"messageID"`
+	Message                 messageOutput      `json:"message"`
+	FeeInfo                 feeInfoOutput      `json:"feeInfo"`
+}
+
+type receiveCrossChainMessageOutput struct {
+	OriginBlockchainID blockchainIDOutput `json:"originBlockchainID"`
+	MessageID          string             `json:"messageID"`
+	Deliverer          string             `json:"deliverer"`
+	RewardRedeemer     string             `json:"rewardRedeemer"`
+	Message            messageOutput      `json:"message"`
+}
+
+type addFeeAmountOutput struct {
+	DestinationBlockchainID blockchainIDOutput `json:"destinationBlockchainID"`
+	MessageID               string             `json:"messageID"`
+	UpdatedFeeInfo          feeInfoOutput      `json:"updatedFeeInfo"`
+}
+
+type messageExecutionFailedOutput struct {
+	OriginBlockchainID blockchainIDOutput `json:"originBlockchainID"`
+	MessageID          string             `json:"messageID"`
+	Message            messageOutput      `json:"message"`
+}
+
+type messageExecutedOutput struct {
+	OriginBlockchainID blockchainIDOutput `json:"originBlockchainID"`
+	MessageID          string             `json:"messageID"`
+}
+
+type relayerRewardsRedeemedOutput struct {
+	Redeemer string `json:"redeemer"`
+	Asset    string `json:"asset"`
+	Amount   string `json:"amount"`
+}
+
+// eventOutput is a decoded Teleporter event, along with the location of its log if known.
+// The location fields are nil only for events decoded without a log.
+type eventOutput struct {
+	Name         string              `json:"name"`
+	BlockchainID *blockchainIDOutput `json:"blockchainID,omitempty"`
+	BlockNumber  *uint64             `json:"blockNumber,omitempty"`
+	TxHash       *string             `json:"txHash,omitempty"`
+	LogIndex     *uint               `json:"logIndex,omitempty"`
+	// Removed is set when the log was removed from the chain by a reorg
+	Removed bool        `json:"removed,omitempty"`
+	Event   interface{} `json:"event"`
+}
+
+type warpMessageOutput struct {
+	WarpMessageID      string              `json:"warpMessageID"`
+	NetworkID          uint32              `json:"networkID"`
+	SourceBlockchainID blockchainIDOutput  `json:"sourceBlockchainID"`
+	AddressedCall      addressedCallOutput `json:"addressedCall"`
+	Message            messageOutput       `json:"message"`
+}
+
+type transactionOutput struct {
+	TxHash       string              `json:"txHash"`
+	BlockNumber  uint64              `json:"blockNumber"`
+	Status       uint64              `json:"status"`
+	Events       []eventOutput       `json:"events"`
+	WarpMessages []warpMessageOutput `json:"warpMessages"`
+	// Warnings lists the logs of the transaction that could not be decoded
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func newBlockchainIDOutput(blockchainID ids.ID) blockchainIDOutput {
+	return blockchainIDOutput{
+		CB58: blockchainID.String(),
+		Hex:  hexutil.Encode(blockchainID[:]),
+	}
+}
+
+func bigIntString(i *big.Int) string {
+	if i == nil {
+		return "0"
+	}
+	return i.String()
+}
+
+func newFeeInfoOutput(feeInfo teleportermessenger.TeleporterFeeInfo) feeInfoOutput {
+	return feeInfoOutput{
+		FeeTokenAddress: feeInfo.FeeTokenAddress.Hex(),
+		Amount:          bigIntString(feeInfo.Amount),
+	}
+}
+
+func newMessageOutput(message teleportermessenger.TeleporterMessage) messageOutput {
+	out := messageOutput{
+		MessageID:               bigIntString(message.MessageID),
+		SenderAddress:           message.SenderAddress.Hex(),
+		DestinationBlockchainID: newBlockchainIDOutput(message.DestinationBlockchainID),
+		DestinationAddress:      message.DestinationAddress.Hex(),
+		RequiredGasLimit:        bigIntString(message.RequiredGasLimit),
+		AllowedRelayerAddresses: []string{},
+		Receipts:                []receiptOutput{},
+		Message:                 hexutil.Encode(message.Message),
+		Payload:                 decodePayload(message.DestinationAddress, message.Message),
+	}
+	for _, relayer := range message.AllowedRelayerAddresses {
+		out.AllowedRelayerAddresses = append(out.AllowedRelayerAddresses, relayer.Hex())
+	}
+	for _, receipt := range message.Receipts {
+		out.Receipts = append(out.Receipts, newReceiptOutput(receipt))
+	}
+	return out
+}
+
+func newReceiptOutput(receipt teleportermessenger.TeleporterMessageReceipt) receiptOutput {
+	return receiptOutput{
+		ReceivedMessageID:    bigIntString(receipt.ReceivedMessageID),
+		RelayerRewardAddress: receipt.RelayerRewardAddress.Hex(),
+	}
+}
+
+// newEventFieldsOutput converts a decoded Teleporter event to its output schema
+func newEventFieldsOutput(event interface{}) interface{} {
+	switch e := event.(type) {
+	case *teleportermessenger.TeleporterMessengerSendCrossChainMessage:
+		return sendCrossChainMessageOutput{
+			DestinationBlockchainID: newBlockchainIDOutput(e.DestinationBlockchainID),
+			MessageID:               bigIntString(e.MessageID),
+			Message:                 newMessageOutput(e.Message),
+			FeeInfo:                 newFeeInfoOutput(e.FeeInfo),
+		}
+	case *teleportermessenger.TeleporterMessengerReceiveCrossChainMessage:
+		return receiveCrossChainMessageOutput{
+			OriginBlockchainID: newBlockchainIDOutput(e.OriginBlockchainID),
+			MessageID:          bigIntString(e.MessageID),
+			Deliverer:          e.Deliverer.Hex(),
+			RewardRedeemer:     e.RewardRedeemer.Hex(),
+			Message:            newMessageOutput(e.Message),
+		}
+	case *teleportermessenger.TeleporterMessengerAddFeeAmount:
+		return addFeeAmountOutput{
+			DestinationBlockchainID: newBlockchainIDOutput(e.DestinationBlockchainID),
+			MessageID:               bigIntString(e.MessageID),
+			UpdatedFeeInfo:          newFeeInfoOutput(e.UpdatedFeeInfo),
+		}
+	case *teleportermessenger.TeleporterMessengerMessageExecutionFailed:
+		return messageExecutionFailedOutput{
+			OriginBlockchainID: newBlockchainIDOutput(e.OriginBlockchainID),
+			MessageID:          bigIntString(e.MessageID),
+			Message:            newMessageOutput(e.Message),
+		}
+	case *teleportermessenger.TeleporterMessengerMessageExecuted:
+		return messageExecutedOutput{
+			OriginBlockchainID: newBlockchainIDOutput(e.OriginBlockchainID),
+			MessageID:          bigIntString(e.MessageID),
+		}
+	case *teleportermessenger.TeleporterMessengerRelayerRewardsRedeemed:
+		return relayerRewardsRedeemedOutput{
+			Redeemer: e.Redeemer.Hex(),
+			Asset:    e.Asset.Hex(),
+			Amount:   bigIntString(e.Amount),
+		}
+	case *teleportermessenger.TeleporterMessage:
+		return newMessageOutput(*e)
+	default:
+		return event
+	}
+}
+
+// newEventOutput converts a decoded Teleporter event to its output schema. If log is non-nil,
+// the location of the log is included.
+func newEventOutput(name string, event interface{}, log *types.Log) eventOutput {
+	out := eventOutput{
+		Name:  name,
+		Event: newEventFieldsOutput(event),
+	}
+	if log != nil {
+		blockNumber, txHash, logIndex := log.BlockNumber, log.TxHash.Hex(), log.Index
+		out.BlockNumber = &blockNumber
+		out.TxHash = &txHash
+		out.LogIndex = &logIndex
+		out.Removed = log.Removed
+	}
+	return out
+}
+
+// newWarpMessageOutput converts an unsigned Warp message carrying a Teleporter message in an
+// AddressedCall payload to its output schema
+func newWarpMessageOutput(
+	unsignedMsg *avalancheWarp.UnsignedMessage,
+	message *teleportermessenger.TeleporterMessage,
+) warpMessageOutput {
+	warpMessageID := unsignedMsg.ID()
+	out := warpMessageOutput{
+		WarpMessageID:      hexutil.Encode(warpMessageID[:]),
+		NetworkID:          unsignedMsg.NetworkID,
+		SourceBlockchainID: newBlockchainIDOutput(unsignedMsg.SourceChainID),
+		Message:            newMessageOutput(*message),
+	}
+	// The Teleporter message was unpacked from the AddressedCall, so the payload parses.
+	if addressedCall, err := warpPayload.ParseAddressedCall(unsignedMsg.Payload); err == nil {
+		out.AddressedCall = addressedCallOutput{
+			SourceAddress: hexutil.Encode(addressedCall.SourceAddress),
+			Payload:       hexutil.Encode(addressedCall.Payload),
+		}
+	}
+	return out
+}
+
+func hashString(hash *common.Hash) string {
+	if hash == nil {
+		return ""
+	}
+	return hash.Hex()
+}
+
+//
+// Output rendering
+//
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case textFormat, jsonFormat, yamlFormat, tableFormat:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %s, must be one of %s, %s, %s, %s",
+			format, textFormat, jsonFormat, yamlFormat, tableFormat)
+	}
+}
+
+// writeOutput writes the result v of a command to the command's standard output in the
+// selected output format
+func writeOutput(cmd *cobra.Command, v interface{}) {
+	cobra.CheckErr(renderOutput(cmd.OutOrStdout(), v, false))
+}
+
+// writeStreamOutput writes one of a stream of results to the command's standard output.
+// JSON results are written one per line, and YAML results as separate documents.
+func writeStreamOutput(cmd *cobra.Command, v interface{}) {
+	cobra.CheckErr(renderOutput(cmd.OutOrStdout(), v, true))
+}
+
+func renderOutput(w io.Writer, v interface{}, stream bool) error {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if outputFormat == jsonFormat {
+		if !stream {
+			b, err = json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	// Every other format is rendered from the JSON representation, so that all formats
+	// share the same schema and field order.
+	node, err := jsonToNode(b)
+	if err != nil {
+		return err
+	}
+	switch outputFormat {
+	case yamlFormat:
+		if stream {
+			if _, err := fmt.Fprintln(w, "---"); err != nil {
+				return err
+			}
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(node); err != nil {
+			return err
+		}
+		return enc.Close()
+	case tableFormat:
+		return renderTable(w, node)
+	default:
+		return renderText(w, node)
+	}
+}
+
+// jsonToNode parses JSON into a YAML node, preserving the order of object fields
+func jsonToNode(b []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	node := doc.Content[0]
+	clearNodeStyle(node)
+	return node, nil
+}
+
+// clearNodeStyle resets the JSON flow style and quoting of a node and its children,
+// so that it is rendered in block style
+func clearNodeStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearNodeStyle(child)
+	}
+}
+
+// flattenNode returns the scalar values of a node keyed by their dotted path
+func flattenNode(prefix string, node *yaml.Node) [][2]string {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			return [][2]string{{prefix, "{}"}}
+		}
+		var fields [][2]string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fields = append(fields, flattenNode(join(node.Content[i].Value), node.Content[i+1])...)
+		}
+		return fields
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			return [][2]string{{prefix, "[]"}}
+		}
+		var fields [][2]string
+		for i, child := range node.Content {
+			fields = append(fields, flattenNode(fmt.Sprintf("%s[%d]", prefix, i), child)...)
+		}
+		return fields
+	default:
+		if node.Tag == "!!null" {
+			return [][2]string{{prefix, ""}}
+		}
+		return [][2]string{{prefix, node.Value}}
+	}
+}
+
+// renderText writes a node as aligned "key: value" lines
+func renderText(w io.Writer, node *yaml.Node) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	for _, field := range flattenNode("", node) {
+		fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1])
+	}
+	return tw.Flush()
+}
+
+// renderTable writes a list of objects as a table with one row per object and one column
+// per top level field. Any other node is written as a two column table of its flattened fields.
+func renderTable(w io.Writer, node *yaml.Node) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if node.Kind == yaml.SequenceNode && len(node.Content) > 0 && node.Content[0].Kind == yaml.MappingNode {
+		var header []string
+		for i := 0; i < len(node.Content[0].Content); i += 2 {
+			header = append(header, strings.ToUpper(node.Content[0].Content[i].Value))
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, row := range node.Content {
+			var cells []string
+			for i := 0; i+1 < len(row.Content); i += 2 {
+				cell, err := tableCell(row.Content[i+1])
+				if err != nil {
+					return err
+				}
+				cells = append(cells, cell)
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	}
+
+	fmt.Fprintln(tw, "FIELD\tVALUE")
+	for _, field := range flattenNode("", node) {
+		fmt.Fprintf(tw, "%s\t%s\n", field[0], field[1])
+	}
+	return tw.Flush()
+}
+
+// tableCell renders a node on a single line, using YAML flow style for nested values
+func tableCell(node *yaml.Node) (string, error) {
+	if node.Kind == yaml.ScalarNode {
+		if node.Tag == "!!null" {
+			return "", nil
+		}
+		return node.Value, nil
+	}
+	flow := *node
+	flow.Style = yaml.FlowStyle
+	b, err := yaml.Marshal(&flow)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core/types"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderOutput(t *testing.T) {
+	defer func() { outputFormat = textFormat }()
+
+	event := newEventOutput("MessageExecuted", &teleportermessenger.TeleporterMessengerMessageExecuted{
+		OriginBlockchainID: ids.ID{1},
+		MessageID:          big.NewInt(10),
+	}, nil)
+	blockchainID := newBlockchainIDOutput(ids.ID{1})
+
+	var tests = []struct {
+		name   string
+		format string
+		value  interface{}
+		stream bool
+		out    string
+	}{
+		{
+			name:   "json",
+			format: jsonFormat,
+			value:  event,
+			out: `{
+  "name": "MessageExecuted",
+  "event": {
+    "originBlockchainID": {
+      "cb58": "` + blockchainID.CB58 + `",
+      "hex": "` + blockchainID.Hex + `"
+    },
+    "messageID": "10"
+  }
+}
+`,
+		},
+		{
+			name:   "json stream",
+			format: jsonFormat,
+			value:  []string{"a", "b"},
+			stream: true,
+			out:    "[\"a\",\"b\"]\n",
+		},
+		{
+			name:   "yaml",
+			format: yamlFormat,
+			value:  event,
+			out: `name: MessageExecuted
+event:
+  originBlockchainID:
+    cb58: ` + blockchainID.CB58 + `
+    hex: ` + blockchainID.Hex + `
+  messageID: "10"
+`,
+		},
+		{
+			name:   "yaml stream",
+			format: yamlFormat,
+			value:  map[string]bool{"delivered": true},
+			stream: true,
+			out:    "---\ndelivered: true\n",
+		},
+		{
+			name:   "text",
+			format: textFormat,
+			value:  event,
+			out: `name:                          MessageExecuted
+event.originBlockchainID.cb58: ` + blockchainID.CB58 + `
+event.originBlockchainID.hex:  ` + blockchainID.Hex + `
+event.messageID:               10
+`,
+		},
+		{
+			name:   "table of objects",
+			format: tableFormat,
+			value: []feeInfoOutput{
+				{FeeTokenAddress: "0x01", Amount: "1"},
+				{FeeTokenAddress: "0x02", Amount: "20"},
+			},
+			out: `FEETOKENADDRESS  AMOUNT
+0x01             1
+0x02             20
+`,
+		},
+		{
+			name:   "table of fields",
+			format: tableFormat,
+			value:  receiptOutput{ReceivedMessageID: "1", RelayerRewardAddress: "0x01"},
+			out: `FIELD                 VALUE
+receivedMessageID     1
+relayerRewardAddress  0x01
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFormat = tt.format
+			var buf bytes.Buffer
+			require.NoError(t, renderOutput(&buf, tt.value, tt.stream))
+			require.Equal(t, tt.out, buf.String())
+		})
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range []string{textFormat, jsonFormat, yamlFormat, tableFormat} {
+		require.NoError(t, validateOutputFormat(format))
+	}
+	require.ErrorContains(t, validateOutputFormat("xml"), "invalid output format xml")
+}
+
+func TestEventOutputLocation(t *testing.T) {
+	event := &teleportermessenger.TeleporterMessengerMessageExecuted{
+		OriginBlockchainID: ids.ID{1},
+		MessageID:          big.NewInt(10),
+	}
+
+	// The first log of the genesis block keeps its zero block number and log index
+	b, err := json.Marshal(newEventOutput("MessageExecuted", event, &types.Log{TxHash: common.Hash{1}}))
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &fields))
+	require.Equal(t, float64(0), fields["blockNumber"])
+	require.Equal(t, float64(0), fields["logIndex"])
+	require.Equal(t, common.Hash{1}.Hex(), fields["txHash"])
+
+	// Events decoded without a log have no location
+	b, err = json.Marshal(newEventOutput("MessageExecuted", event, nil))
+	require.NoError(t, err)
+	fields = nil
+	require.NoError(t, json.Unmarshal(b, &fields))
+	require.NotContains(t, fields, "blockNumber")
+	require.NotContains(t, fields, "logIndex")
+	require.NotContains(t, fields, "txHash")
+}
@@ -0,0 +1,323 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	payloadABIFiles []string
+
+	// flagPayloadDecoders are read from the --payload-abi files, and take precedence over the
+	// decoders of the config file
+	flagPayloadDecoders map[common.Address]payloadDecoder
+	// payloadDecoders is built on first use, so that commands that do not decode messages
+	// do not fail on an invalid config file
+	payloadDecoders map[common.Address]payloadDecoder
+)
+
+// payloadDecoder decodes the application payload of a Teleporter message
+type payloadDecoder interface {
+	decode(payload []byte) (interface{}, error)
+}
+
+// argumentsDecoder decodes a payload ABI encoded as a list of arguments, i.e. abi.encode(a, b)
+type argumentsDecoder struct {
+	args abi.Arguments
+}
+
+func (d argumentsDecoder) decode(payload []byte) (interface{}, error) {
+	values, err := d.args.Unpack(payload)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		name := d.args[i].Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		out[name] = payloadValue(value)
+	}
+	return out, nil
+}
+
+// actionDecoder decodes a payload encoded as abi.encode(uint8 action, bytes actionData), where
+// the encoding of the action data depends on the action. This is the encoding of the bridge apps.
+type actionDecoder struct {
+	actions  []string
+	decoders map[string]argumentsDecoder
+}
+
+func (d actionDecoder) decode(payload []byte) (interface{}, error) {
+	values, err := actionArguments.Unpack(payload)
+	if err != nil {
+		return nil, err
+	}
+	action := values[0].(uint8)
+	if int(action) >= len(d.actions) {
+		return nil, fmt.Errorf("unknown action %d", action)
+	}
+	name := d.actions[action]
+	out, err := d.decoders[name].decode(values[1].([]byte))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s action data: %w", name, err)
+	}
+	out.(map[string]interface{})["action"] = name
+	return out, nil
+}
+
+var actionArguments = newArguments(
+	abi.ArgumentMarshaling{Name: "action", Type: "uint8"},
+	abi.ArgumentMarshaling{Name: "actionData", Type: "bytes"},
+)
+
+// builtinPayloadDecoders decode the payloads of the cross chain applications in this repository,
+// keyed by the name used to register them for a receiver address
+var builtinPayloadDecoders = map[string]payloadDecoder{
+	// Messages received by ERC20Bridge, see IERC20Bridge.BridgeAction
+	"erc20-bridge": actionDecoder{
+		actions: []string{"Create", "Mint", "Transfer"},
+		decoders: map[string]argumentsDecoder{
+			"Create": {newArguments(
+				abi.ArgumentMarshaling{Name: "nativeContractAddress", Type: "address"},
+				abi.ArgumentMarshaling{Name: "nativeName", Type: "string"},
+				abi.ArgumentMarshaling{Name: "nativeSymbol", Type: "string"},
+				abi.ArgumentMarshaling{Name: "nativeDecimals", Type: "uint8"},
+			)},
+			"Mint": {newArguments(
+				abi.ArgumentMarshaling{Name: "nativeContractAddress", Type: "address"},
+				abi.ArgumentMarshaling{Name: "recipient", Type: "address"},
+				abi.ArgumentMarshaling{Name: "amount", Type: "uint256"},
+			)},
+			"Transfer": {newArguments(
+				abi.ArgumentMarshaling{Name: "destinationBlockchainID", Type: "bytes32"},
+				abi.ArgumentMarshaling{Name: "destinationBridgeAddress", Type: "address"},
+				abi.ArgumentMarshaling{Name: "nativeContractAddress", Type: "address"},
+				abi.ArgumentMarshaling{Name: "recipient", Type: "address"},
+				abi.ArgumentMarshaling{Name: "totalAmount", Type: "uint256"},
+				abi.ArgumentMarshaling{Name: "secondaryFeeAmount", Type: "uint256"},
+			)},
+		},
+	},
+	// Messages received by NativeTokenSource and ERC20TokenSource, see ITokenSource.SourceAction
+	"native-token-source": actionDecoder{
+		actions: []string{"Unlock", "Burn"},
+		decoders: map[string]argumentsDecoder{
+			"Unlock": {newArguments(
+				abi.ArgumentMarshaling{Name: "recipient", Type: "address"},
+				abi.ArgumentMarshaling{Name: "amount", Type: "uint256"},
+			)},
+			"Burn": {newArguments(
+				abi.ArgumentMarshaling{Name: "totalBurnedTxFees", Type: "uint256"},
+			)},
+		},
+	},
+	// Messages received by NativeTokenDestination
+	"native-token-destination": argumentsDecoder{newArguments(
+		abi.ArgumentMarshaling{Name: "recipient", Type: "address"},
+		abi.ArgumentMarshaling{Name: "amount", Type: "uint256"},
+	)},
+	// Messages received by ExampleCrossChainMessenger
+	"example-messenger": argumentsDecoder{newArguments(
+		abi.ArgumentMarshaling{Name: "message", Type: "string"},
+	)},
+	// Messages published by BlockHashPublisher to BlockHashReceiver
+	"block-hash": argumentsDecoder{newArguments(
+		abi.ArgumentMarshaling{Name: "blockHeight", Type: "uint256"},
+		abi.ArgumentMarshaling{Name: "blockHash", Type: "bytes32"},
+	)},
+}
+
+// newArguments builds ABI arguments from their JSON ABI descriptions. Panics on an invalid
+// type, so it must only be used for static descriptions.
+func newArguments(marshalings ...abi.ArgumentMarshaling) abi.Arguments {
+	args, err := parseArguments(marshalings)
+	if err != nil {
+		panic(err)
+	}
+	return args
+}
+
+func parseArguments(marshalings []abi.ArgumentMarshaling) (abi.Arguments, error) {
+	args := make(abi.Arguments, 0, len(marshalings))
+	for _, m := range marshalings {
+		typ, err := abi.NewType(m.Type, m.InternalType, m.Components)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type of argument %s: %w", m.Name, err)
+		}
+		args = append(args, abi.Argument{Name: m.Name, Type: typ})
+	}
+	return args, nil
+}
+
+// payloadDecoderInput is the payload decoder registered for a receiver address, either the
+// name of a built-in decoder or the JSON ABI description of the payload arguments
+type payloadDecoderInput struct {
+	payloadDecoder
+}
+
+func (p *payloadDecoderInput) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		decoder, ok := builtinPayloadDecoders[name]
+		if !ok {
+			return fmt.Errorf("unknown payload decoder %s, expected one of %s", name, builtinPayloadDecoderNames())
+		}
+		p.payloadDecoder = decoder
+		return nil
+	}
+	var marshalings []abi.ArgumentMarshaling
+	if err := json.Unmarshal(data, &marshalings); err != nil {
+		return fmt.Errorf("payload decoder must be a decoder name or a list of ABI arguments: %w", err)
+	}
+	args, err := parseArguments(marshalings)
+	if err != nil {
+		return err
+	}
+	p.payloadDecoder = argumentsDecoder{args}
+	return nil
+}
+
+func builtinPayloadDecoderNames() string {
+	names := make([]string, 0, len(builtinPayloadDecoders))
+	for name := range builtinPayloadDecoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// parsePayloadDecoders converts payload decoders keyed by receiver address
+func parsePayloadDecoders(inputs map[string]payloadDecoderInput) (map[common.Address]payloadDecoder, error) {
+	decoders := make(map[common.Address]payloadDecoder, len(inputs))
+	for address, input := range inputs {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid payload decoder address %s", address)
+		}
+		decoders[common.HexToAddress(address)] = input.payloadDecoder
+	}
+	return decoders, nil
+}
+
+// readPayloadDecoderFiles reads the --payload-abi files, each a JSON or YAML object of payload
+// decoders keyed by receiver address
+func readPayloadDecoderFiles(cmd *cobra.Command, paths []string) (map[common.Address]payloadDecoder, error) {
+	decoders := make(map[common.Address]payloadDecoder)
+	for _, path := range paths {
+		b, err := readInputFile(cmd, path)
+		if err != nil {
+			return nil, err
+		}
+		var inputs map[string]payloadDecoderInput
+		if err := unmarshalInput(b, &inputs); err != nil {
+			return nil, fmt.Errorf("failed to parse payload ABI file %s: %w", path, err)
+		}
+		fileDecoders, err := parsePayloadDecoders(inputs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse payload ABI file %s: %w", path, err)
+		}
+		for address, decoder := range fileDecoders {
+			decoders[address] = decoder
+		}
+	}
+	return decoders, nil
+}
+
+// getPayloadDecoder returns the payload decoder registered for a receiver address, or nil
+func getPayloadDecoder(address common.Address) payloadDecoder {
+	if payloadDecoders == nil {
+		payloadDecoders = make(map[common.Address]payloadDecoder)
+		if cfg, err := loadConfig(); err != nil {
+			logger.Warn("Failed to load the payload decoders of the config file", zap.Error(err))
+		} else {
+			configDecoders, err := parsePayloadDecoders(cfg.PayloadDecoders)
+			if err != nil {
+				logger.Warn("Failed to load the payload decoders of the config file", zap.Error(err))
+			}
+			for a, decoder := range configDecoders {
+				payloadDecoders[a] = decoder
+			}
+		}
+		for a, decoder := range flagPayloadDecoders {
+			payloadDecoders[a] = decoder
+		}
+	}
+	return payloadDecoders[address]
+}
+
+// decodePayload decodes the application payload of a message sent to address, if a decoder is
+// registered for it. Payloads that fail to decode are left undecoded.
+func decodePayload(address common.Address, payload []byte) interface{} {
+	decoder := getPayloadDecoder(address)
+	if decoder == nil {
+		return nil
+	}
+	decoded, err := decoder.decode(payload)
+	if err != nil {
+		logger.Warn("Failed to decode message payload",
+			zap.String("destinationAddress", address.Hex()),
+			zap.Error(err))
+		return nil
+	}
+	return decoded
+}
+
+// payloadValue converts a value unpacked from an ABI encoding to the output schema: addresses,
+// hashes and byte arrays as 0x prefixed hex, and big integers as decimal strings
+func payloadValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case common.Address:
+		return v.Hex()
+	case *big.Int:
+		return bigIntString(v)
+	case []byte:
+		return hexutil.Encode(v)
+	case [32]byte:
+		return hexutil.Encode(v[:])
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Array:
+		// Fixed size byte arrays, i.e. bytes4
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = payloadValue(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.Struct:
+		// Tuples are unpacked to anonymous structs with JSON tags of the component names
+		out := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			name := rv.Type().Field(i).Tag.Get("json")
+			if name == "" {
+				name = rv.Type().Field(i).Name
+			}
+			out[name] = payloadValue(rv.Field(i).Interface())
+		}
+		return out
+	default:
+		return value
+	}
+}
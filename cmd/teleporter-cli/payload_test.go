@@ -0,0 +1,199 @@
+package main
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinPayloadDecoders(t *testing.T) {
+	recipient := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	token := common.HexToAddress("0x0a")
+	pack := func(args abi.Arguments, values ...interface{}) []byte {
+		b, err := args.Pack(values...)
+		require.NoError(t, err)
+		return b
+	}
+	packAction := func(action uint8, actionData []byte) []byte {
+		return pack(actionArguments, action, actionData)
+	}
+	erc20Bridge := builtinPayloadDecoders["erc20-bridge"].(actionDecoder)
+
+	var tests = []struct {
+		name     string
+		decoder  string
+		payload  []byte
+		expected map[string]interface{}
+		err      string
+	}{
+		{
+			name:    "erc20 bridge mint",
+			decoder: "erc20-bridge",
+			payload: packAction(1, pack(erc20Bridge.decoders["Mint"].args, token, recipient, big.NewInt(10))),
+			expected: map[string]interface{}{
+				"action":                "Mint",
+				"nativeContractAddress": token.Hex(),
+				"recipient":             recipient.Hex(),
+				"amount":                "10",
+			},
+		},
+		{
+			name:    "erc20 bridge create",
+			decoder: "erc20-bridge",
+			payload: packAction(0, pack(erc20Bridge.decoders["Create"].args, token, "Token", "TOK", uint8(18))),
+			expected: map[string]interface{}{
+				"action":                "Create",
+				"nativeContractAddress": token.Hex(),
+				"nativeName":            "Token",
+				"nativeSymbol":          "TOK",
+				"nativeDecimals":        uint8(18),
+			},
+		},
+		{
+			name:    "erc20 bridge unknown action",
+			decoder: "erc20-bridge",
+			payload: packAction(3, []byte{}),
+			err:     "unknown action 3",
+		},
+		{
+			name:    "native token source burn",
+			decoder: "native-token-source",
+			payload: packAction(1, pack(newArguments(abi.ArgumentMarshaling{Type: "uint256"}), big.NewInt(5))),
+			expected: map[string]interface{}{
+				"action":            "Burn",
+				"totalBurnedTxFees": "5",
+			},
+		},
+		{
+			name:    "native token destination",
+			decoder: "native-token-destination",
+			payload: pack(builtinPayloadDecoders["native-token-destination"].(argumentsDecoder).args,
+				recipient, big.NewInt(7)),
+			expected: map[string]interface{}{
+				"recipient": recipient.Hex(),
+				"amount":    "7",
+			},
+		},
+		{
+			name:     "example messenger",
+			decoder:  "example-messenger",
+			payload:  pack(newArguments(abi.ArgumentMarshaling{Type: "string"}), "hello"),
+			expected: map[string]interface{}{"message": "hello"},
+		},
+		{
+			name:    "block hash",
+			decoder: "block-hash",
+			payload: pack(builtinPayloadDecoders["block-hash"].(argumentsDecoder).args,
+				big.NewInt(100), common.HexToHash("0x01")),
+			expected: map[string]interface{}{
+				"blockHeight": "100",
+				"blockHash":   common.HexToHash("0x01").Hex(),
+			},
+		},
+		{
+			name:    "invalid payload",
+			decoder: "block-hash",
+			payload: []byte{1, 2, 3},
+			err:     "abi: cannot marshal in to go type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := builtinPayloadDecoders[tt.decoder].decode(tt.payload)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, decoded)
+		})
+	}
+}
+
+func TestPayloadDecoderFiles(t *testing.T) {
+	logger = logging.NoLog{}
+	writeTestConfig(t, `
+payload-decoders:
+  "0x0000000000000000000000000000000000000001": example-messenger
+  "0x0000000000000000000000000000000000000002": example-messenger
+`)
+	path := filepath.Join(t.TempDir(), "payloads.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+"0x0000000000000000000000000000000000000002":
+  - name: ids
+    type: uint64[]
+  - name: transfer
+    type: tuple
+    components:
+      - name: to
+        type: address
+      - name: data
+        type: bytes4
+`), 0o600))
+
+	decoders, err := readPayloadDecoderFiles(rootCmd, []string{path})
+	require.NoError(t, err)
+	flagPayloadDecoders = decoders
+	payloadDecoders = nil
+	t.Cleanup(func() {
+		flagPayloadDecoders = nil
+		payloadDecoders = nil
+	})
+
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		DestinationAddress:      common.HexToAddress("0x01"),
+		RequiredGasLimit:        big.NewInt(0),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{},
+	}
+
+	// Decoded with the decoder of the config file
+	message.Message, err = newArguments(abi.ArgumentMarshaling{Type: "string"}).Pack("hello")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"message": "hello"}, newMessageOutput(message).Payload)
+
+	// The decoders of the --payload-abi files take precedence over the config file
+	message.DestinationAddress = common.HexToAddress("0x02")
+	args := decoders[message.DestinationAddress].(argumentsDecoder).args
+	transfer := struct {
+		To   common.Address `json:"to"`
+		Data [4]byte        `json:"data"`
+	}{common.HexToAddress("0x03"), [4]byte{1, 2, 3, 4}}
+	message.Message, err = args.Pack([]uint64{1, 2}, transfer)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"ids": []interface{}{uint64(1), uint64(2)},
+		"transfer": map[string]interface{}{
+			"to":   common.HexToAddress("0x03").Hex(),
+			"data": "0x01020304",
+		},
+	}, newMessageOutput(message).Payload)
+
+	// Messages to addresses without a decoder, or that fail to decode, are not decoded
+	message.DestinationAddress = common.HexToAddress("0x04")
+	require.Nil(t, newMessageOutput(message).Payload)
+	message.DestinationAddress = common.HexToAddress("0x01")
+	message.Message = []byte{1}
+	require.Nil(t, newMessageOutput(message).Payload)
+}
+
+func TestInvalidPayloadDecoderFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payloads.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"0x0000000000000000000000000000000000000001": "unknown"}`), 0o600))
+	_, err := readPayloadDecoderFiles(rootCmd, []string{path})
+	require.ErrorContains(t, err, "unknown payload decoder unknown")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"not-an-address": "block-hash"}`), 0o600))
+	_, err = readPayloadDecoderFiles(rootCmd, []string{path})
+	require.ErrorContains(t, err, "invalid payload decoder address not-an-address")
+}
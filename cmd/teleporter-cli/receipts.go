@@ -0,0 +1,265 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	receiptsFeeTokenAddress string
+	receiptsFeeAmount       string
+	receiptsAllowedRelayers []string
+)
+
+// receiptQueueReader reads the receipt queues stored by TeleporterMessenger
+type receiptQueueReader interface {
+	GetReceiptQueueSize(opts *bind.CallOpts, originBlockchainID [32]byte) (*big.Int, error)
+	GetReceiptAtIndex(
+		opts *bind.CallOpts,
+		originBlockchainID [32]byte,
+		index *big.Int,
+	) (teleportermessenger.TeleporterMessageReceipt, error)
+}
+
+type receiptQueueOutput struct {
+	OriginBlockchainID blockchainIDOutput `json:"originBlockchainID"`
+	Size               string             `json:"size"`
+	Receipts           []receiptOutput    `json:"receipts"`
+}
+
+// receiptQueuesOutput is the result of a receipts list command
+type receiptQueuesOutput struct {
+	BlockchainID blockchainIDOutput   `json:"blockchainID"`
+	Queues       []receiptQueueOutput `json:"queues"`
+}
+
+var receiptsCmd = &cobra.Command{
+	Use:   "receipts",
+	Short: "Inspects and sends the receipts of received Teleporter messages",
+	Long: `Inspects and sends the receipts of the Teleporter messages received by the
+--rpc chain. The receipt of a received message is queued per origin chain, and
+is only sent back with the next message to that chain. Relayers are only paid
+their fee once the receipt of the message they delivered is received by the
+origin chain, so on routes without traffic flowing back the receipts have to
+be sent explicitly.`,
+}
+
+var receiptsListCmd = &cobra.Command{
+	Use:   "list --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS [ORIGIN_BLOCKCHAIN_ID...]",
+	Short: "Lists the pending receipts for each origin chain",
+	Long: `Reads the receipt queue of each origin chain with getReceiptQueueSize and
+getReceiptAtIndex, in the order the receipts will be sent. The origin
+blockchain IDs may be given in cb58, as 0x prefixed hex, or as the names of
+chains in the config file. If none are given, the origin chains are those of
+the ReceiveCrossChainMessage logs in the most recent --lookback-blocks blocks.`,
+	Args: cobra.ArbitraryArgs,
+	Run:  receiptsListRun,
+}
+
+var receiptsSendCmd = &cobra.Command{
+	Use: "send --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"[--private-key KEY | --keystore FILE | --external-signer URL] " +
+		"ORIGIN_BLOCKCHAIN_ID MESSAGE_ID...",
+	Short: "Sends the receipts of received messages back to their origin chain",
+	Long: `Calls sendSpecifiedReceipts to send the receipts of the given received message
+IDs back to their origin chain in a new Teleporter message, with an optional
+fee for the relayer that delivers it. If a fee is set, the Teleporter contract
+is first approved to spend the fee token. Prints the SendCrossChainMessage log
+of the message carrying the receipts.`,
+	Args: cobra.MinimumNArgs(2),
+	Run:  receiptsSendRun,
+}
+
+func receiptsListRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	blockchainID, err := getBlockchainID(ctx, client)
+	cobra.CheckErr(err)
+	messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, client)
+	cobra.CheckErr(err)
+
+	out := receiptQueuesOutput{
+		BlockchainID: newBlockchainIDOutput(blockchainID),
+		Queues:       []receiptQueueOutput{},
+	}
+	var originBlockchainIDs []ids.ID
+	for _, arg := range args {
+		originBlockchainID, err := resolveBlockchainID(arg)
+		cobra.CheckErr(err)
+		originBlockchainIDs = append(originBlockchainIDs, originBlockchainID)
+	}
+	if len(originBlockchainIDs) == 0 {
+		originBlockchainIDs, err = discoverOriginChains(ctx, client, lookbackBlocks)
+		cobra.CheckErr(err)
+		logger.Debug("Discovered origin chains", zap.Int("numOriginChains", len(originBlockchainIDs)))
+	}
+	for _, originBlockchainID := range originBlockchainIDs {
+		queue, err := getReceiptQueue(ctx, messenger, originBlockchainID)
+		cobra.CheckErr(err)
+		out.Queues = append(out.Queues, queue)
+	}
+
+	writeOutput(cmd, out)
+	cmd.Println("Receipts list command ran successfully")
+}
+
+func receiptsSendRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	signer, err := newSigner(cmd)
+	cobra.CheckErr(err)
+	originBlockchainID, err := resolveBlockchainID(args[0])
+	cobra.CheckErr(err)
+	var messageIDs []*big.Int
+	for _, arg := range args[1:] {
+		messageID, ok := new(big.Int).SetString(arg, 0)
+		if !ok {
+			cobra.CheckErr(fmt.Errorf("invalid message ID %s", arg))
+		}
+		messageIDs = append(messageIDs, messageID)
+	}
+	feeAmount, ok := new(big.Int).SetString(receiptsFeeAmount, 0)
+	if !ok {
+		cobra.CheckErr(fmt.Errorf("invalid fee amount %s", receiptsFeeAmount))
+	}
+	feeInfo := teleportermessenger.TeleporterFeeInfo{
+		FeeTokenAddress: common.HexToAddress(receiptsFeeTokenAddress),
+		Amount:          feeAmount,
+	}
+	allowedRelayers := []common.Address{}
+	for _, relayer := range receiptsAllowedRelayers {
+		allowedRelayers = append(allowedRelayers, common.HexToAddress(relayer))
+	}
+
+	messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, client)
+	cobra.CheckErr(err)
+	// sendSpecifiedReceipts reverts if any of the messages was not received.
+	for _, messageID := range messageIDs {
+		received, err := messenger.MessageReceived(&bind.CallOpts{Context: ctx}, originBlockchainID, messageID)
+		cobra.CheckErr(err)
+		if !received {
+			cobra.CheckErr(fmt.Errorf("message %s from %s was not received", messageID, originBlockchainID))
+		}
+	}
+	logger.Debug("Sending receipts",
+		zap.String("originBlockchainID", originBlockchainID.String()),
+		zap.Int("numReceipts", len(messageIDs)))
+
+	err = approveERC20(ctx, client, signer, feeInfo.FeeTokenAddress, teleporterAddress, feeInfo.Amount)
+	cobra.CheckErr(err)
+
+	data, err := teleportermessenger.PackSendSpecifiedReceipts(originBlockchainID, messageIDs, feeInfo, allowedRelayers)
+	cobra.CheckErr(err)
+	receipt, err := sendContractTransaction(ctx, client, signer, teleporterAddress, data)
+	cobra.CheckErr(err)
+
+	event, err := getEventFromLogs(receipt.Logs, messenger.ParseSendCrossChainMessage)
+	cobra.CheckErr(err)
+	if ids.ID(event.DestinationBlockchainID) != originBlockchainID {
+		cobra.CheckErr(fmt.Errorf("receipts were sent to %s, not to %s",
+			ids.ID(event.DestinationBlockchainID), originBlockchainID))
+	}
+
+	writeOutput(cmd, newEventOutput(teleportermessenger.SendCrossChainMessage.String(), event, &event.Raw))
+	cmd.Println("Receipts send command ran successfully")
+}
+
+// getReceiptQueue reads the pending receipts of the messages received from originBlockchainID
+func getReceiptQueue(
+	ctx context.Context,
+	messenger receiptQueueReader,
+	originBlockchainID ids.ID,
+) (receiptQueueOutput, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	size, err := messenger.GetReceiptQueueSize(opts, originBlockchainID)
+	if err != nil {
+		return receiptQueueOutput{}, err
+	}
+	queue := receiptQueueOutput{
+		OriginBlockchainID: newBlockchainIDOutput(originBlockchainID),
+		Size:               size.String(),
+		Receipts:           []receiptOutput{},
+	}
+	for i := big.NewInt(0); i.Cmp(size) < 0; i.Add(i, common.Big1) {
+		receipt, err := messenger.GetReceiptAtIndex(opts, originBlockchainID, i)
+		if err != nil {
+			return receiptQueueOutput{}, err
+		}
+		queue.Receipts = append(queue.Receipts, newReceiptOutput(receipt))
+	}
+	return queue, nil
+}
+
+// discoverOriginChains returns the origin chains of the messages received in the most recent
+// lookback blocks, from their ReceiveCrossChainMessage logs, sorted by blockchain ID
+func discoverOriginChains(ctx context.Context, client latestLogReader, lookback uint64) ([]ids.ID, error) {
+	query := interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress},
+		Topics: [][]common.Hash{{
+			teleporterABI.Events[teleportermessenger.ReceiveCrossChainMessage.String()].ID,
+		}},
+	}
+	found := make(map[ids.ID]bool)
+	err := filterRecentLogs(ctx, client, query, lookback, func(log types.Log) error {
+		_, event, err := parseTeleporterLog(log.Topics, log.Data)
+		if err != nil {
+			logger.Warn("Failed to parse Teleporter log", zap.String("txHash", log.TxHash.Hex()), zap.Error(err))
+			return nil
+		}
+		if e, ok := event.(*teleportermessenger.TeleporterMessengerReceiveCrossChainMessage); ok {
+			found[e.OriginBlockchainID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	originBlockchainIDs := make([]ids.ID, 0, len(found))
+	for originBlockchainID := range found {
+		originBlockchainIDs = append(originBlockchainIDs, originBlockchainID)
+	}
+	sort.Slice(originBlockchainIDs, func(i, j int) bool {
+		return bytes.Compare(originBlockchainIDs[i][:], originBlockchainIDs[j][:]) < 0
+	})
+	return originBlockchainIDs, nil
+}
+
+func init() {
+	rootCmd.AddCommand(receiptsCmd)
+	receiptsCmd.AddCommand(receiptsListCmd)
+	receiptsCmd.AddCommand(receiptsSendCmd)
+	receiptsCmd.PersistentFlags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint to connect to the node")
+	address := receiptsCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addChainFlag(receiptsCmd)
+	receiptsListCmd.Flags().Uint64Var(&lookbackBlocks, "lookback-blocks", defaultLookbackBlocks,
+		"Number of recent blocks to search for received messages when no origin chains are given")
+	addSignerFlags(receiptsSendCmd.Flags(), "to sign the transaction")
+	receiptsSendCmd.Flags().StringVar(&receiptsFeeTokenAddress, "fee-token", "", "Address of the ERC20 fee token")
+	receiptsSendCmd.Flags().StringVar(&receiptsFeeAmount, "fee-amount", "0",
+		"Amount of the fee token to pay the relayer")
+	receiptsSendCmd.Flags().StringSliceVar(&receiptsAllowedRelayers, "allowed-relayers", []string{},
+		"Addresses of the relayers allowed to deliver the receipts. Any relayer is allowed if empty")
+	err := receiptsCmd.MarkPersistentFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = receiptsCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	receiptsCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return rpcPreRunE(cmd, args, address)
+	}
+}
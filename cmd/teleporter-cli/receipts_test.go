@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptsCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "send no message IDs",
+			args: []string{"receipts", "send", "extra"},
+			err:  fmt.Errorf("requires at least 2 arg(s), only received 1"),
+		},
+		{
+			name: "list help",
+			args: []string{"receipts", "list", "--help"},
+			err:  nil,
+			out:  "Reads the receipt queue of each origin chain with getReceiptQueueSize",
+		},
+		{
+			name: "send help",
+			args: []string{"receipts", "send", "--help"},
+			err:  nil,
+			out:  "Calls sendSpecifiedReceipts to send the receipts of the given received message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+// staticReceiptQueue is the receipt queue of a single origin chain
+type staticReceiptQueue struct {
+	originBlockchainID ids.ID
+	receipts           []teleportermessenger.TeleporterMessageReceipt
+}
+
+func (q *staticReceiptQueue) GetReceiptQueueSize(_ *bind.CallOpts, originBlockchainID [32]byte) (*big.Int, error) {
+	if ids.ID(originBlockchainID) != q.originBlockchainID {
+		return big.NewInt(0), nil
+	}
+	return big.NewInt(int64(len(q.receipts))), nil
+}
+
+func (q *staticReceiptQueue) GetReceiptAtIndex(
+	_ *bind.CallOpts,
+	originBlockchainID [32]byte,
+	index *big.Int,
+) (teleportermessenger.TeleporterMessageReceipt, error) {
+	if ids.ID(originBlockchainID) != q.originBlockchainID || index.Cmp(big.NewInt(int64(len(q.receipts)))) >= 0 {
+		return teleportermessenger.TeleporterMessageReceipt{}, errors.New("receipt index out of bounds")
+	}
+	return q.receipts[index.Int64()], nil
+}
+
+func TestGetReceiptQueue(t *testing.T) {
+	origin := ids.ID{1}
+	relayer := common.HexToAddress("0xa0")
+	queue := &staticReceiptQueue{
+		originBlockchainID: origin,
+		receipts: []teleportermessenger.TeleporterMessageReceipt{
+			{ReceivedMessageID: big.NewInt(3), RelayerRewardAddress: relayer},
+			{ReceivedMessageID: big.NewInt(1), RelayerRewardAddress: relayer},
+		},
+	}
+
+	out, err := getReceiptQueue(context.Background(), queue, origin)
+	require.NoError(t, err)
+	require.Equal(t, newBlockchainIDOutput(origin), out.OriginBlockchainID)
+	require.Equal(t, "2", out.Size)
+	// Receipts are listed in the order they will be sent
+	require.Equal(t, []receiptOutput{
+		{ReceivedMessageID: "3", RelayerRewardAddress: relayer.Hex()},
+		{ReceivedMessageID: "1", RelayerRewardAddress: relayer.Hex()},
+	}, out.Receipts)
+
+	// An empty queue has an empty list rather than none
+	out, err = getReceiptQueue(context.Background(), queue, ids.ID{2})
+	require.NoError(t, err)
+	require.Equal(t, "0", out.Size)
+	require.NotNil(t, out.Receipts)
+	require.Empty(t, out.Receipts)
+}
+
+func TestDiscoverOriginChains(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		RequiredGasLimit:        big.NewInt(0),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{},
+	}
+	received := func(origin ids.ID, block uint64) types.Log {
+		log := packTeleporterLog(t, "ReceiveCrossChainMessage",
+			[]common.Hash{common.Hash(origin), common.BigToHash(big.NewInt(1)), {}},
+			common.HexToAddress("0xa0"), message)
+		log.BlockNumber = block
+		return log
+	}
+	// Blocks 0 to 10, of which the lookback covers 5 to 10
+	chain := newStaticChain(0, 1, 11,
+		received(ids.ID{4}, 2),
+		received(ids.ID{3}, 5),
+		received(ids.ID{1}, 7),
+		received(ids.ID{3}, 9),
+		// An unknown event of the Teleporter contract
+		types.Log{Topics: []common.Hash{{1}}, BlockNumber: 10},
+	)
+
+	originBlockchainIDs, err := discoverOriginChains(context.Background(), chain, 5)
+	require.NoError(t, err)
+	require.Equal(t, []ids.ID{{1}, {3}}, originBlockchainIDs)
+
+	originBlockchainIDs, err = discoverOriginChains(context.Background(), newStaticChain(0, 1, 11), 5)
+	require.NoError(t, err)
+	require.Empty(t, originBlockchainIDs)
+}
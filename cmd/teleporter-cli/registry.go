@@ -0,0 +1,335 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	teleporterregistry "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/upgrades/TeleporterRegistry"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// teleporterUpgradeableABI is the subset of the TeleporterUpgradeable ABI read by check-app.
+// TeleporterUpgradeable is abstract, so it has no generated bindings.
+const teleporterUpgradeableABI = `[
+	{"type": "function", "name": "teleporterRegistry", "stateMutability": "view",
+		"inputs": [], "outputs": [{"name": "", "type": "address"}]},
+	{"type": "function", "name": "getMinTeleporterVersion", "stateMutability": "view",
+		"inputs": [], "outputs": [{"name": "", "type": "uint256"}]},
+	{"type": "function", "name": "isTeleporterAddressPaused", "stateMutability": "view",
+		"inputs": [{"name": "teleporterAddress", "type": "address"}], "outputs": [{"name": "", "type": "bool"}]}
+]`
+
+var (
+	registryAddress   common.Address
+	registryFromBlock uint64
+	registryChunkSize uint64
+)
+
+type registryVersionOutput struct {
+	Version         string `json:"version"`
+	ProtocolAddress string `json:"protocolAddress"`
+}
+
+type addProtocolVersionOutput struct {
+	Version         string `json:"version"`
+	ProtocolAddress string `json:"protocolAddress"`
+}
+
+type latestVersionUpdatedOutput struct {
+	OldVersion string `json:"oldVersion"`
+	NewVersion string `json:"newVersion"`
+}
+
+// registryOutput is the result of a registry list command
+type registryOutput struct {
+	RegistryAddress string                  `json:"registryAddress"`
+	LatestVersion   string                  `json:"latestVersion"`
+	Versions        []registryVersionOutput `json:"versions"`
+	History         []eventOutput           `json:"history"`
+}
+
+// registryResolveOutput is the result of a registry resolve command
+type registryResolveOutput struct {
+	Version         string `json:"version"`
+	ProtocolAddress string `json:"protocolAddress"`
+	Latest          bool   `json:"latest"`
+}
+
+type appVersionOutput struct {
+	Version         string `json:"version"`
+	ProtocolAddress string `json:"protocolAddress"`
+	Paused          bool   `json:"paused"`
+	CanReceive      bool   `json:"canReceive"`
+}
+
+// checkAppOutput is the result of a registry check-app command
+type checkAppOutput struct {
+	AppAddress           string             `json:"appAddress"`
+	RegistryAddress      string             `json:"registryAddress"`
+	MinTeleporterVersion string             `json:"minTeleporterVersion"`
+	Versions             []appVersionOutput `json:"versions"`
+}
+
+// registryVersion is a protocol version registered in a TeleporterRegistry
+type registryVersion struct {
+	version         *big.Int
+	protocolAddress common.Address
+}
+
+// registryVersionReader is the subset of the TeleporterRegistry bindings used to walk its versions
+type registryVersionReader interface {
+	LatestVersion(opts *bind.CallOpts) (*big.Int, error)
+	GetAddressFromVersion(opts *bind.CallOpts, version *big.Int) (common.Address, error)
+}
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Inspects a TeleporterRegistry and the apps that use it",
+	Long: `Inspects the Teleporter versions registered in a TeleporterRegistry, and
+whether a TeleporterUpgradeable app can receive messages from each of them.`,
+}
+
+var registryListCmd = &cobra.Command{
+	Use:   "list --rpc RPC_URL --teleporter-registry-address REGISTRY_ADDRESS",
+	Short: "Lists the registered Teleporter versions",
+	Long: `Walks the registered versions of a TeleporterRegistry from latestVersion down
+with getAddressFromVersion, and prints the AddProtocolVersion and
+LatestVersionUpdated logs of the registry from --from-block to the latest
+block.`,
+	Args: cobra.NoArgs,
+	Run:  registryListRun,
+}
+
+var registryResolveCmd = &cobra.Command{
+	Use:   "resolve --rpc RPC_URL --teleporter-registry-address REGISTRY_ADDRESS (VERSION | ADDRESS)",
+	Short: "Maps a Teleporter address to its version, or a version to its address",
+	Long: `Given a 0x prefixed Teleporter contract address, prints its version in the
+registry with getVersionFromAddress. Given a version number, prints its
+address with getAddressFromVersion.`,
+	Args: cobra.ExactArgs(1),
+	Run:  registryResolveRun,
+}
+
+var registryCheckAppCmd = &cobra.Command{
+	Use:   "check-app --rpc RPC_URL APP_ADDRESS",
+	Short: "Reports which Teleporter versions a TeleporterUpgradeable app can receive messages from",
+	Long: `Reads the registry, minimum Teleporter version and paused Teleporter addresses
+of a TeleporterUpgradeable app, and reports for each registered version
+whether the app can currently receive messages delivered by it. An app only
+receives messages from versions at or above its minimum version whose
+addresses it has not paused.`,
+	Args: cobra.ExactArgs(1),
+	Run:  registryCheckAppRun,
+}
+
+func registryListRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	registry, err := teleporterregistry.NewTeleporterRegistry(registryAddress, client)
+	cobra.CheckErr(err)
+	latestVersion, versions, err := getRegistryVersions(ctx, registry)
+	cobra.CheckErr(err)
+
+	out := registryOutput{
+		RegistryAddress: registryAddress.Hex(),
+		LatestVersion:   latestVersion.String(),
+		Versions:        []registryVersionOutput{},
+		History:         []eventOutput{},
+	}
+	for _, v := range versions {
+		out.Versions = append(out.Versions, registryVersionOutput{
+			Version:         v.version.String(),
+			ProtocolAddress: v.protocolAddress.Hex(),
+		})
+	}
+
+	registryABI, err := teleporterregistry.TeleporterRegistryMetaData.GetAbi()
+	cobra.CheckErr(err)
+	toBlock, err := client.BlockNumber(ctx)
+	cobra.CheckErr(err)
+	query := interfaces.FilterQuery{
+		Addresses: []common.Address{registryAddress},
+		Topics: [][]common.Hash{{
+			registryABI.Events["AddProtocolVersion"].ID,
+			registryABI.Events["LatestVersionUpdated"].ID,
+		}},
+	}
+	err = filterLogsInChunks(ctx, client, query, registryFromBlock, toBlock, registryChunkSize,
+		func(log types.Log) error {
+			event, err := parseRegistryLog(registry, log)
+			if err != nil {
+				logger.Warn("Failed to parse registry log", zap.String("txHash", log.TxHash.Hex()), zap.Error(err))
+				return nil
+			}
+			out.History = append(out.History, event)
+			return nil
+		})
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, out)
+	cmd.Println("Registry list command ran successfully")
+}
+
+func registryResolveRun(cmd *cobra.Command, args []string) {
+	opts := &bind.CallOpts{Context: context.Background()}
+
+	registry, err := teleporterregistry.NewTeleporterRegistry(registryAddress, client)
+	cobra.CheckErr(err)
+
+	var out registryResolveOutput
+	if common.IsHexAddress(args[0]) && strings.HasPrefix(args[0], "0x") {
+		protocolAddress := common.HexToAddress(args[0])
+		version, err := registry.GetVersionFromAddress(opts, protocolAddress)
+		cobra.CheckErr(err)
+		out = registryResolveOutput{Version: version.String(), ProtocolAddress: protocolAddress.Hex()}
+	} else {
+		version, ok := new(big.Int).SetString(args[0], 10)
+		if !ok {
+			cobra.CheckErr(fmt.Errorf("invalid version or address %s", args[0]))
+		}
+		protocolAddress, err := registry.GetAddressFromVersion(opts, version)
+		cobra.CheckErr(err)
+		out = registryResolveOutput{Version: version.String(), ProtocolAddress: protocolAddress.Hex()}
+	}
+	latestVersion, err := registry.LatestVersion(opts)
+	cobra.CheckErr(err)
+	out.Latest = out.Version == latestVersion.String()
+
+	writeOutput(cmd, out)
+	cmd.Println("Registry resolve command ran successfully")
+}
+
+func registryCheckAppRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	opts := &bind.CallOpts{Context: ctx}
+
+	if !common.IsHexAddress(args[0]) {
+		cobra.CheckErr(fmt.Errorf("invalid app address %s", args[0]))
+	}
+	appAddress := common.HexToAddress(args[0])
+	upgradeableABI, err := abi.JSON(strings.NewReader(teleporterUpgradeableABI))
+	cobra.CheckErr(err)
+	app := bind.NewBoundContract(appAddress, upgradeableABI, client, nil, nil)
+
+	var results []interface{}
+	err = app.Call(opts, &results, "teleporterRegistry")
+	cobra.CheckErr(err)
+	appRegistryAddress := *abi.ConvertType(results[0], new(common.Address)).(*common.Address)
+	err = app.Call(opts, &results, "getMinTeleporterVersion")
+	cobra.CheckErr(err)
+	minVersion := *abi.ConvertType(results[0], new(*big.Int)).(**big.Int)
+
+	registry, err := teleporterregistry.NewTeleporterRegistry(appRegistryAddress, client)
+	cobra.CheckErr(err)
+	_, versions, err := getRegistryVersions(ctx, registry)
+	cobra.CheckErr(err)
+
+	out := checkAppOutput{
+		AppAddress:           appAddress.Hex(),
+		RegistryAddress:      appRegistryAddress.Hex(),
+		MinTeleporterVersion: minVersion.String(),
+		Versions:             []appVersionOutput{},
+	}
+	for _, v := range versions {
+		err = app.Call(opts, &results, "isTeleporterAddressPaused", v.protocolAddress)
+		cobra.CheckErr(err)
+		paused := *abi.ConvertType(results[0], new(bool)).(*bool)
+		out.Versions = append(out.Versions, appVersionOutput{
+			Version:         v.version.String(),
+			ProtocolAddress: v.protocolAddress.Hex(),
+			Paused:          paused,
+			CanReceive:      v.version.Cmp(minVersion) >= 0 && !paused,
+		})
+	}
+
+	writeOutput(cmd, out)
+	cmd.Println("Registry check-app command ran successfully")
+}
+
+// getRegistryVersions returns the latest version of a registry, and its registered versions from
+// the latest down. Versions may be registered out of order and with gaps, so versions that are
+// not registered are skipped.
+func getRegistryVersions(ctx context.Context, registry registryVersionReader) (*big.Int, []registryVersion, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	latestVersion, err := registry.LatestVersion(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	var versions []registryVersion
+	for version := new(big.Int).Set(latestVersion); version.Sign() > 0; version.Sub(version, common.Big1) {
+		protocolAddress, err := registry.GetAddressFromVersion(opts, version)
+		if err != nil {
+			// getAddressFromVersion reverts for versions that are not registered.
+			if strings.Contains(err.Error(), "execution reverted") {
+				logger.Debug("Version not registered", zap.String("version", version.String()))
+				continue
+			}
+			return nil, nil, err
+		}
+		versions = append(versions, registryVersion{
+			version:         new(big.Int).Set(version),
+			protocolAddress: protocolAddress,
+		})
+	}
+	return latestVersion, versions, nil
+}
+
+// parseRegistryLog decodes an AddProtocolVersion or LatestVersionUpdated log of a registry
+func parseRegistryLog(registry *teleporterregistry.TeleporterRegistry, log types.Log) (eventOutput, error) {
+	if event, err := registry.ParseAddProtocolVersion(log); err == nil {
+		return newEventOutput("AddProtocolVersion", addProtocolVersionOutput{
+			Version:         bigIntString(event.Version),
+			ProtocolAddress: event.ProtocolAddress.Hex(),
+		}, &log), nil
+	}
+	event, err := registry.ParseLatestVersionUpdated(log)
+	if err != nil {
+		return eventOutput{}, err
+	}
+	return newEventOutput("LatestVersionUpdated", latestVersionUpdatedOutput{
+		OldVersion: bigIntString(event.OldVersion),
+		NewVersion: bigIntString(event.NewVersion),
+	}, &log), nil
+}
+
+func init() {
+	rootCmd.AddCommand(registryCmd)
+	registryCmd.AddCommand(registryListCmd)
+	registryCmd.AddCommand(registryResolveCmd)
+	registryCmd.AddCommand(registryCheckAppCmd)
+	registryCmd.PersistentFlags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint to connect to the node")
+	addChainFlag(registryCmd)
+	err := registryCmd.MarkPersistentFlagRequired("rpc")
+	cobra.CheckErr(err)
+
+	for _, subCmd := range []*cobra.Command{registryListCmd, registryResolveCmd} {
+		subCmd.Flags().String("teleporter-registry-address", "", "TeleporterRegistry contract address")
+		err = subCmd.MarkFlagRequired("teleporter-registry-address")
+		cobra.CheckErr(err)
+	}
+	registryListCmd.Flags().Uint64Var(&registryFromBlock, "from-block", 0,
+		"First block to read the registry history from")
+	registryListCmd.Flags().Uint64Var(&registryChunkSize, "chunk-size", defaultLogChunkSize,
+		"Maximum number of blocks to query logs for in a single request")
+
+	registryCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := rpcPreRunE(cmd, args, nil); err != nil {
+			return err
+		}
+		if flag := cmd.Flags().Lookup("teleporter-registry-address"); flag != nil {
+			registryAddress = common.HexToAddress(flag.Value.String())
+		}
+		return nil
+	}
+}
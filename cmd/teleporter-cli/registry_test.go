@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "resolve no args",
+			args: []string{"registry", "resolve"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "check-app no args",
+			args: []string{"registry", "check-app"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "list help",
+			args: []string{"registry", "list", "--help"},
+			err:  nil,
+			out:  "Walks the registered versions of a TeleporterRegistry from latestVersion down",
+		},
+		{
+			name: "check-app help",
+			args: []string{"registry", "check-app", "--help"},
+			err:  nil,
+			out:  "Reads the registry, minimum Teleporter version and paused Teleporter addresses",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+// staticRegistry is a registry with fixed versions, that reverts like TeleporterRegistry
+// for versions that are not registered
+type staticRegistry struct {
+	latestVersion int64
+	versions      map[int64]common.Address
+	err           error
+}
+
+func (r *staticRegistry) LatestVersion(_ *bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(r.latestVersion), nil
+}
+
+func (r *staticRegistry) GetAddressFromVersion(_ *bind.CallOpts, version *big.Int) (common.Address, error) {
+	if r.err != nil {
+		return common.Address{}, r.err
+	}
+	address, ok := r.versions[version.Int64()]
+	if !ok {
+		return common.Address{}, errors.New("execution reverted: TeleporterRegistry: version not found")
+	}
+	return address, nil
+}
+
+func TestGetRegistryVersions(t *testing.T) {
+	logger = logging.NoLog{}
+
+	registry := &staticRegistry{
+		latestVersion: 4,
+		versions: map[int64]common.Address{
+			1: common.HexToAddress("0x01"),
+			2: common.HexToAddress("0x02"),
+			4: common.HexToAddress("0x04"),
+		},
+	}
+	latestVersion, versions, err := getRegistryVersions(context.Background(), registry)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(4), latestVersion)
+	require.Equal(t, []registryVersion{
+		{version: big.NewInt(4), protocolAddress: common.HexToAddress("0x04")},
+		{version: big.NewInt(2), protocolAddress: common.HexToAddress("0x02")},
+		{version: big.NewInt(1), protocolAddress: common.HexToAddress("0x01")},
+	}, versions)
+
+	// Errors other than reverts are returned
+	registry.err = errors.New("connection refused")
+	_, _, err = getRegistryVersions(context.Background(), registry)
+	require.ErrorContains(t, err, "connection refused")
+}
@@ -0,0 +1,245 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	predicateutils "github.com/ava-labs/subnet-evm/predicate"
+	warpBackend "github.com/ava-labs/subnet-evm/warp"
+	"github.com/ava-labs/subnet-evm/x/warp"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	gasUtils "github.com/ava-labs/teleporter/utils/gas-utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	nodeURI              string
+	quorumNumerator      uint64
+	relayerRewardAddress string
+	relayMessageID       string
+
+	errNoTeleporterWarpLog = errors.New("no Teleporter Warp message found in transaction logs")
+)
+
+// relayOutput is the result of a relay command
+type relayOutput struct {
+	WarpMessage      warpMessageOutput `json:"warpMessage"`
+	AlreadyDelivered bool              `json:"alreadyDelivered"`
+	TxHash           string            `json:"txHash,omitempty"`
+	NumSigners       int               `json:"numSigners,omitempty"`
+	Event            *eventOutput      `json:"event,omitempty"`
+}
+
+var relayCmd = &cobra.Command{
+	Use: "relay --source-rpc RPC_URL --dest-rpc RPC_URL --node-uri NODE_URI " +
+		"--teleporter-address CONTRACT_ADDRESS " +
+		"[--private-key KEY | --keystore FILE | --external-signer URL] " +
+		"TRANSACTION_HASH",
+	Short: "Manually relays a Teleporter message from a source transaction",
+	Long: `Given the hash of a source chain transaction that sent a Teleporter message,
+this command finds the Warp message to the --dest-rpc chain in the
+transaction's logs, fetches its aggregate signature from the source chain's
+Warp API on --node-uri, and delivers it to the destination chain by calling
+receiveCrossChainMessage with the signed message in the transaction's predicate
+access list. If the transaction sends several messages to the destination
+chain, --message-id selects the one to relay.
+Does nothing if the message has already been delivered.`,
+	Args: cobra.ExactArgs(1),
+	Run:  relayRun,
+}
+
+func relayRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	signer, err := newSigner(cmd)
+	cobra.CheckErr(err)
+	rewardAddress := signer.Address()
+	if relayerRewardAddress != "" {
+		rewardAddress = common.HexToAddress(relayerRewardAddress)
+	}
+
+	destinationBlockchainID, err := getBlockchainID(ctx, destClient)
+	cobra.CheckErr(err)
+	var messageID *big.Int
+	if relayMessageID != "" {
+		var ok bool
+		messageID, ok = new(big.Int).SetString(relayMessageID, 0)
+		if !ok {
+			cobra.CheckErr(fmt.Errorf("invalid message ID %s", relayMessageID))
+		}
+	}
+	sourceReceipt, err := sourceClient.TransactionReceipt(ctx, common.HexToHash(args[0]))
+	cobra.CheckErr(err)
+	unsignedMsg, teleporterMessage, err := findTeleporterWarpMessage(
+		sourceReceipt.Logs, destinationBlockchainID, messageID,
+	)
+	cobra.CheckErr(err)
+	logger.Debug("Found Teleporter Warp message",
+		zap.String("warpMessageID", unsignedMsg.ID().String()),
+		zap.String("teleporterMessageID", teleporterMessage.MessageID.String()))
+	out := relayOutput{WarpMessage: newWarpMessageOutput(unsignedMsg, teleporterMessage)}
+
+	destMessenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, destClient)
+	cobra.CheckErr(err)
+	delivered, err := destMessenger.MessageReceived(
+		&bind.CallOpts{Context: ctx}, unsignedMsg.SourceChainID, teleporterMessage.MessageID,
+	)
+	cobra.CheckErr(err)
+	if delivered {
+		out.AlreadyDelivered = true
+		writeOutput(cmd, out)
+		cmd.Println("Relay command ran successfully")
+		return
+	}
+
+	logger.Debug("Fetching aggregate signature from the source chain validators")
+	warpClient, err := warpBackend.NewClient(nodeURI, unsignedMsg.SourceChainID.String())
+	cobra.CheckErr(err)
+	signedWarpMessageBytes, err := warpClient.GetMessageAggregateSignature(ctx, unsignedMsg.ID(), quorumNumerator)
+	cobra.CheckErr(err)
+	signedMessage, err := avalancheWarp.ParseMessage(signedWarpMessageBytes)
+	cobra.CheckErr(err)
+
+	chainID, err := destClient.ChainID(ctx)
+	cobra.CheckErr(err)
+	gasFeeCap, gasTipCap, nonce, err := calculateTxParams(ctx, destClient, signer.Address())
+	cobra.CheckErr(err)
+	destinationTx, numSigners, err := newRelayTx(
+		chainID, nonce, gasFeeCap, gasTipCap, signedMessage, teleporterMessage.RequiredGasLimit, rewardAddress,
+	)
+	cobra.CheckErr(err)
+	receipt, err := signAndSendTransaction(ctx, destClient, destinationTx, signer, chainID)
+	cobra.CheckErr(err)
+
+	receiveEvent, err := getEventFromLogs(receipt.Logs, destMessenger.ParseReceiveCrossChainMessage)
+	cobra.CheckErr(err)
+	event := newEventOutput(teleportermessenger.ReceiveCrossChainMessage.String(), receiveEvent, &receiveEvent.Raw)
+	out.TxHash = receipt.TxHash.Hex()
+	out.NumSigners = numSigners
+	out.Event = &event
+	writeOutput(cmd, out)
+	cmd.Println("Relay command ran successfully")
+}
+
+// newRelayTx builds the transaction delivering signedMessage to the Teleporter contract, with the
+// message in the Warp predicate of its access list. The gas limit covers the verification of the
+// signature, which depends on the number of signers, and the required gas limit of the message.
+func newRelayTx(
+	chainID *big.Int,
+	nonce uint64,
+	gasFeeCap *big.Int,
+	gasTipCap *big.Int,
+	signedMessage *avalancheWarp.Message,
+	requiredGasLimit *big.Int,
+	rewardAddress common.Address,
+) (*types.Transaction, int, error) {
+	numSigners, err := signedMessage.Signature.NumSigners()
+	if err != nil {
+		return nil, 0, err
+	}
+	gasLimit, err := gasUtils.CalculateReceiveMessageGasLimit(numSigners, requiredGasLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The signed message is the only Warp predicate of the transaction, at message index 0.
+	callData, err := teleportermessenger.PackReceiveCrossChainMessage(0, rewardAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+	tx := predicateutils.NewPredicateTx(
+		chainID,
+		nonce,
+		&teleporterAddress,
+		gasLimit,
+		gasFeeCap,
+		gasTipCap,
+		common.Big0,
+		callData,
+		types.AccessList{},
+		warp.ContractAddress,
+		signedMessage.Bytes(),
+	)
+	return tx, numSigners, nil
+}
+
+// findTeleporterWarpMessage returns the Warp message in logs that was sent by the Teleporter contract
+// to destinationBlockchainID. If the logs hold several such messages, messageID selects one of them.
+func findTeleporterWarpMessage(
+	logs []*types.Log,
+	destinationBlockchainID ids.ID,
+	messageID *big.Int,
+) (*avalancheWarp.UnsignedMessage, *teleportermessenger.TeleporterMessage, error) {
+	var (
+		found      *avalancheWarp.UnsignedMessage
+		foundMsg   *teleportermessenger.TeleporterMessage
+		messageIDs []string
+	)
+	for _, log := range logs {
+		if !isTeleporterWarpLog(log) {
+			continue
+		}
+		unsignedMsg, teleporterMessage, err := parseTeleporterWarpLog(log)
+		if err != nil {
+			logger.Warn("Failed to parse Warp log", zap.Uint("logIndex", log.Index), zap.Error(err))
+			continue
+		}
+		if ids.ID(teleporterMessage.DestinationBlockchainID) != destinationBlockchainID {
+			continue
+		}
+		if messageID != nil && teleporterMessage.MessageID.Cmp(messageID) != 0 {
+			continue
+		}
+		found, foundMsg = unsignedMsg, teleporterMessage
+		messageIDs = append(messageIDs, teleporterMessage.MessageID.String())
+	}
+	switch len(messageIDs) {
+	case 0:
+		return nil, nil, fmt.Errorf("%w to %s", errNoTeleporterWarpLog, destinationBlockchainID)
+	case 1:
+		return found, foundMsg, nil
+	default:
+		return nil, nil, fmt.Errorf("transaction sends messages %s to %s, select one with --message-id",
+			strings.Join(messageIDs, ", "), destinationBlockchainID)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(relayCmd)
+	relayCmd.PersistentFlags().StringVar(&sourceRPCEndpoint, "source-rpc", "", "RPC endpoint of the source chain")
+	relayCmd.PersistentFlags().StringVar(&destRPCEndpoint, "dest-rpc", "", "RPC endpoint of the destination chain")
+	address := relayCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addFromToFlags(relayCmd)
+	relayCmd.Flags().StringVar(&nodeURI, "node-uri", "",
+		"URI of a source chain node serving the Warp API, i.e. http://127.0.0.1:9650")
+	relayCmd.Flags().Uint64Var(&quorumNumerator, "quorum-num", params.WarpDefaultQuorumNumerator,
+		"Quorum numerator of the aggregate signature, out of 100")
+	addSignerFlags(relayCmd.Flags(), "to sign the transaction")
+	relayCmd.Flags().StringVar(&relayerRewardAddress, "reward-address", "",
+		"Address to credit the relayer reward to. Defaults to the signer's address")
+	relayCmd.Flags().StringVar(&relayMessageID, "message-id", "",
+		"ID of the message to relay, if the transaction sends several messages to the destination chain")
+	err := relayCmd.MarkPersistentFlagRequired("source-rpc")
+	cobra.CheckErr(err)
+	err = relayCmd.MarkPersistentFlagRequired("dest-rpc")
+	cobra.CheckErr(err)
+	err = relayCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	err = relayCmd.MarkFlagRequired("node-uri")
+	cobra.CheckErr(err)
+	relayCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return sourceDestPreRunE(cmd, args, address)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/x/warp"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	gasUtils "github.com/ava-labs/teleporter/utils/gas-utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"relay"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"relay", "--help"},
+			err:  nil,
+			out:  "Given the hash of a source chain transaction that sent a Teleporter message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestFindTeleporterWarpMessage(t *testing.T) {
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	warpLog := func(messageID int64, destinationBlockchainID ids.ID) *types.Log {
+		messageBytes, err := teleportermessenger.PackTeleporterMessage(teleportermessenger.TeleporterMessage{
+			MessageID:               big.NewInt(messageID),
+			DestinationBlockchainID: destinationBlockchainID,
+			RequiredGasLimit:        big.NewInt(0),
+			AllowedRelayerAddresses: []common.Address{},
+			Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+			Message:                 []byte{},
+		})
+		require.NoError(t, err)
+		addressedCall, err := warpPayload.NewAddressedCall(teleporterAddress.Bytes(), messageBytes)
+		require.NoError(t, err)
+		unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{1}, addressedCall.Bytes())
+		require.NoError(t, err)
+		topics, data, err := warp.PackSendWarpMessageEvent(
+			teleporterAddress, common.Hash(unsignedMsg.ID()), unsignedMsg.Bytes())
+		require.NoError(t, err)
+		return &types.Log{Address: warp.ContractAddress, Topics: topics, Data: data}
+	}
+	logs := []*types.Log{
+		warpLog(1, ids.ID{2}),
+		warpLog(2, ids.ID{3}),
+		warpLog(3, ids.ID{3}),
+		// A log of another contract
+		{Address: common.HexToAddress("0x01")},
+	}
+
+	var tests = []struct {
+		name                    string
+		destinationBlockchainID ids.ID
+		messageID               *big.Int
+		expectedMessageID       int64
+		err                     string
+	}{
+		{
+			name:                    "not first log",
+			destinationBlockchainID: ids.ID{2},
+			expectedMessageID:       1,
+		},
+		{
+			name:                    "selected by message ID",
+			destinationBlockchainID: ids.ID{3},
+			messageID:               big.NewInt(3),
+			expectedMessageID:       3,
+		},
+		{
+			name:                    "several messages",
+			destinationBlockchainID: ids.ID{3},
+			err:                     "transaction sends messages 2, 3",
+		},
+		{
+			name:                    "other destination",
+			destinationBlockchainID: ids.ID{4},
+			err:                     errNoTeleporterWarpLog.Error(),
+		},
+		{
+			name:                    "message ID to other destination",
+			destinationBlockchainID: ids.ID{2},
+			messageID:               big.NewInt(2),
+			err:                     errNoTeleporterWarpLog.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsignedMsg, teleporterMessage, err := findTeleporterWarpMessage(
+				logs, tt.destinationBlockchainID, tt.messageID)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, ids.ID{1}, unsignedMsg.SourceChainID)
+			require.Equal(t, tt.expectedMessageID, teleporterMessage.MessageID.Int64())
+			require.Equal(t, tt.destinationBlockchainID, ids.ID(teleporterMessage.DestinationBlockchainID))
+		})
+	}
+}
+
+func TestNewRelayTx(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	messageBytes, err := teleportermessenger.PackTeleporterMessage(teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		DestinationBlockchainID: ids.ID{2},
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{},
+	})
+	require.NoError(t, err)
+	addressedCall, err := warpPayload.NewAddressedCall(teleporterAddress.Bytes(), messageBytes)
+	require.NoError(t, err)
+	unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{1}, addressedCall.Bytes())
+	require.NoError(t, err)
+	rewardAddress := common.HexToAddress("0xa0")
+
+	var tests = []struct {
+		name       string
+		signers    []byte
+		numSigners int
+		err        bool
+	}{
+		{
+			name:       "one signer",
+			signers:    []byte{0b1},
+			numSigners: 1,
+		},
+		{
+			name:       "nine signers",
+			signers:    []byte{0b1, 0xff},
+			numSigners: 9,
+		},
+		{
+			name:    "non-canonical signers",
+			signers: []byte{0, 0b1},
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signedMsg, err := avalancheWarp.NewMessage(unsignedMsg, &avalancheWarp.BitSetSignature{Signers: tt.signers})
+			require.NoError(t, err)
+			tx, numSigners, err := newRelayTx(
+				big.NewInt(1), 5, big.NewInt(2), big.NewInt(1), signedMsg, big.NewInt(100_000), rewardAddress)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.numSigners, numSigners)
+
+			expectedGasLimit, err := gasUtils.CalculateReceiveMessageGasLimit(tt.numSigners, big.NewInt(100_000))
+			require.NoError(t, err)
+			require.Equal(t, expectedGasLimit, tx.Gas())
+			require.Equal(t, teleporterAddress, *tx.To())
+			require.Equal(t, uint64(5), tx.Nonce())
+
+			// The call reads the signed message from the Warp predicate at its message index.
+			require.Len(t, getWarpPredicates(tx.AccessList()), 1)
+			out, err := decodeCalldata(tx.Data(), tx.AccessList())
+			require.NoError(t, err)
+			require.Equal(t, "receiveCrossChainMessage", out.Method)
+			require.Empty(t, out.Warnings)
+			require.NotNil(t, out.WarpMessage)
+			require.Equal(t, "1", out.WarpMessage.Message.MessageID)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var retryTxHash string
+
+var retryCmd = &cobra.Command{
+	Use:   "retry",
+	Short: "Retries the execution or the sending of a Teleporter message",
+	Long: `Retries a Teleporter message whose execution failed on the destination chain,
+or re-sends the Warp message of a Teleporter message that was never delivered.
+The original TeleporterMessage is loaded from the logs of the chain, either
+from the given transaction or by searching the most recent --lookback-blocks
+blocks for the message ID.`,
+}
+
+var retryExecutionCmd = &cobra.Command{
+	Use: "execution --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"[--private-key KEY | --keystore FILE | --external-signer URL] " +
+		"[ORIGIN_BLOCKCHAIN_ID MESSAGE_ID | --tx TRANSACTION_HASH]",
+	Short: "Retries the execution of a message that failed on the destination chain",
+	Long: `Loads the TeleporterMessage from the MessageExecutionFailed log of the
+destination chain, and calls retryMessageExecution with it. The --rpc endpoint
+is the destination chain, and --tx is the hash of the transaction that
+delivered the message. The origin blockchain ID may be given in cb58, as 0x
+prefixed hex, or as the name of a chain in the config file.`,
+	Args: retryArgs,
+	Run:  retryExecutionRun,
+}
+
+var retrySendCmd = &cobra.Command{
+	Use: "send --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"[--private-key KEY | --keystore FILE | --external-signer URL] " +
+		"[DESTINATION_BLOCKCHAIN_ID MESSAGE_ID | --tx TRANSACTION_HASH]",
+	Short: "Re-sends the Warp message of a message that was not delivered",
+	Long: `Loads the TeleporterMessage from the SendCrossChainMessage log of the source
+chain, and calls retrySendCrossChainMessage with it, which emits a new Warp
+message for relayers to deliver. The --rpc endpoint is the source chain, and
+--tx is the hash of the transaction that sent the message. The destination
+blockchain ID may be given in cb58, as 0x prefixed hex, or as the name of a
+chain in the config file.`,
+	Args: retryArgs,
+	Run:  retrySendRun,
+}
+
+func retryArgs(cmd *cobra.Command, args []string) error {
+	if retryTxHash != "" {
+		return cobra.NoArgs(cmd, args)
+	}
+	return cobra.ExactArgs(2)(cmd, args)
+}
+
+// parseMessageIDArgs parses the blockchain ID and message ID arguments of the commands that act on a single message
+func parseMessageIDArgs(args []string) (ids.ID, *big.Int, error) {
+	blockchainID, err := resolveBlockchainID(args[0])
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+	messageID, ok := new(big.Int).SetString(args[1], 0)
+	if !ok {
+		return ids.Empty, nil, fmt.Errorf("invalid message ID %s", args[1])
+	}
+	return blockchainID, messageID, nil
+}
+
+func retryExecutionRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	signer, err := newSigner(cmd)
+	cobra.CheckErr(err)
+	messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, client)
+	cobra.CheckErr(err)
+
+	var failedEvent *teleportermessenger.TeleporterMessengerMessageExecutionFailed
+	if retryTxHash != "" {
+		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(retryTxHash))
+		cobra.CheckErr(err)
+		failedEvent, err = getEventFromLogs(receipt.Logs, messenger.ParseMessageExecutionFailed)
+		cobra.CheckErr(err)
+	} else {
+		originBlockchainID, messageID, err := parseMessageIDArgs(args)
+		cobra.CheckErr(err)
+		startBlock, err := getLookbackStartBlock(ctx, client, lookbackBlocks)
+		cobra.CheckErr(err)
+		it, err := messenger.FilterMessageExecutionFailed(
+			&bind.FilterOpts{Start: startBlock, Context: ctx},
+			[][32]byte{originBlockchainID},
+			[]*big.Int{messageID},
+		)
+		cobra.CheckErr(err)
+		for it.Next() {
+			failedEvent = it.Event
+		}
+		cobra.CheckErr(it.Error())
+		if failedEvent == nil {
+			cobra.CheckErr(fmt.Errorf("no MessageExecutionFailed log found for message %s from %s in the last %d blocks",
+				messageID, originBlockchainID, lookbackBlocks))
+		}
+	}
+	originBlockchainID := ids.ID(failedEvent.OriginBlockchainID)
+	logger.Debug("Found failed message execution",
+		zap.String("originBlockchainID", originBlockchainID.String()),
+		zap.String("messageID", failedEvent.MessageID.String()),
+		zap.String("txHash", failedEvent.Raw.TxHash.Hex()))
+
+	// The failed message hash is cleared once the execution is successfully retried.
+	failedMessageHash, err := messenger.ReceivedFailedMessageHashes(
+		&bind.CallOpts{Context: ctx}, originBlockchainID, failedEvent.MessageID,
+	)
+	cobra.CheckErr(err)
+	if failedMessageHash == [32]byte{} {
+		cobra.CheckErr(fmt.Errorf("message %s from %s has no failed execution to retry",
+			failedEvent.MessageID, originBlockchainID))
+	}
+
+	data, err := teleportermessenger.PackRetryMessageExecution(originBlockchainID, failedEvent.Message)
+	cobra.CheckErr(err)
+	receipt, err := sendContractTransaction(ctx, client, signer, teleporterAddress, data)
+	cobra.CheckErr(err)
+
+	event, err := getEventFromLogs(receipt.Logs, messenger.ParseMessageExecuted)
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, newEventOutput(teleportermessenger.MessageExecuted.String(), event, &event.Raw))
+	cmd.Println("Retry execution command ran successfully")
+}
+
+func retrySendRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	signer, err := newSigner(cmd)
+	cobra.CheckErr(err)
+	messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, client)
+	cobra.CheckErr(err)
+
+	var sendEvent *teleportermessenger.TeleporterMessengerSendCrossChainMessage
+	if retryTxHash != "" {
+		receipt, err := client.TransactionReceipt(ctx, common.HexToHash(retryTxHash))
+		cobra.CheckErr(err)
+		sendEvent, err = getEventFromLogs(receipt.Logs, messenger.ParseSendCrossChainMessage)
+		cobra.CheckErr(err)
+	} else {
+		destinationBlockchainID, messageID, err := parseMessageIDArgs(args)
+		cobra.CheckErr(err)
+		startBlock, err := getLookbackStartBlock(ctx, client, lookbackBlocks)
+		cobra.CheckErr(err)
+		it, err := messenger.FilterSendCrossChainMessage(
+			&bind.FilterOpts{Start: startBlock, Context: ctx},
+			[][32]byte{destinationBlockchainID},
+			[]*big.Int{messageID},
+		)
+		cobra.CheckErr(err)
+		// A message that was already retried has several SendCrossChainMessage logs with the same message.
+		for it.Next() {
+			sendEvent = it.Event
+		}
+		cobra.CheckErr(it.Error())
+		if sendEvent == nil {
+			cobra.CheckErr(fmt.Errorf("no SendCrossChainMessage log found for message %s to %s in the last %d blocks",
+				messageID, destinationBlockchainID, lookbackBlocks))
+		}
+	}
+	destinationBlockchainID := ids.ID(sendEvent.DestinationBlockchainID)
+	logger.Debug("Found sent message",
+		zap.String("destinationBlockchainID", destinationBlockchainID.String()),
+		zap.String("messageID", sendEvent.MessageID.String()),
+		zap.String("txHash", sendEvent.Raw.TxHash.Hex()))
+
+	// The message hash is only stored on the source chain until the receipt for the message is received.
+	messageHash, err := messenger.GetMessageHash(
+		&bind.CallOpts{Context: ctx}, destinationBlockchainID, sendEvent.MessageID,
+	)
+	cobra.CheckErr(err)
+	if messageHash == [32]byte{} {
+		cobra.CheckErr(fmt.Errorf("message %s to %s was not sent, or its receipt was already received",
+			sendEvent.MessageID, destinationBlockchainID))
+	}
+
+	data, err := teleportermessenger.PackRetrySendCrossChainMessage(destinationBlockchainID, sendEvent.Message)
+	cobra.CheckErr(err)
+	receipt, err := sendContractTransaction(ctx, client, signer, teleporterAddress, data)
+	cobra.CheckErr(err)
+
+	event, err := getEventFromLogs(receipt.Logs, messenger.ParseSendCrossChainMessage)
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, newEventOutput(teleportermessenger.SendCrossChainMessage.String(), event, &event.Raw))
+	cmd.Println("Retry send command ran successfully")
+}
+
+func init() {
+	rootCmd.AddCommand(retryCmd)
+	retryCmd.AddCommand(retryExecutionCmd)
+	retryCmd.AddCommand(retrySendCmd)
+	retryCmd.PersistentFlags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint to connect to the node")
+	address := retryCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addChainFlag(retryCmd)
+	addSignerFlags(retryCmd.PersistentFlags(), "to sign the transaction")
+	retryCmd.PersistentFlags().StringVar(&retryTxHash, "tx", "",
+		"Hash of the transaction that emitted the message log")
+	retryCmd.PersistentFlags().Uint64Var(&lookbackBlocks, "lookback-blocks", defaultLookbackBlocks,
+		"Number of recent blocks to search for message logs")
+	err := retryCmd.MarkPersistentFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = retryCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	retryCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return rpcPreRunE(cmd, args, address)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "execution no args",
+			args: []string{"retry", "execution"},
+			err:  fmt.Errorf("accepts 2 arg(s), received 0"),
+		},
+		{
+			name: "send too few args",
+			args: []string{"retry", "send", "extra"},
+			err:  fmt.Errorf("accepts 2 arg(s), received 1"),
+		},
+		{
+			name: "execution help",
+			args: []string{"retry", "execution", "--help"},
+			err:  nil,
+			out:  "Loads the TeleporterMessage from the MessageExecutionFailed log",
+		},
+		{
+			name: "send help",
+			args: []string{"retry", "send", "--help"},
+			err:  nil,
+			out:  "Loads the TeleporterMessage from the SendCrossChainMessage log",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestParseMessageIDArgs(t *testing.T) {
+	blockchainID := ids.ID{1}
+	id, messageID, err := parseMessageIDArgs([]string{blockchainID.String(), "0x10"})
+	require.NoError(t, err)
+	require.Equal(t, blockchainID, id)
+	require.Equal(t, big.NewInt(16), messageID)
+
+	_, _, err = parseMessageIDArgs([]string{blockchainID.String(), "ten"})
+	require.ErrorContains(t, err, "invalid message ID ten")
+
+	_, _, err = parseMessageIDArgs([]string{"invalid", "1"})
+	require.Error(t, err)
+}
@@ -0,0 +1,287 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	rewardsRPCEndpoints []string
+	rewardsRelayer      string
+	rewardsFeeTokens    []string
+	rewardsAllTokens    bool
+	rewardsFromBlock    uint64
+	rewardsChunkSize    uint64
+)
+
+type relayerRewardOutput struct {
+	BlockchainID    blockchainIDOutput `json:"blockchainID"`
+	FeeTokenAddress string             `json:"feeTokenAddress"`
+	Amount          string             `json:"amount"`
+}
+
+// relayerRewardsOutput is the result of a rewards show command
+type relayerRewardsOutput struct {
+	Relayer string                `json:"relayer"`
+	Rewards []relayerRewardOutput `json:"rewards"`
+}
+
+// redeemOutput is the result of a rewards redeem command
+type redeemOutput struct {
+	Redeemer    string        `json:"redeemer"`
+	Redemptions []eventOutput `json:"redemptions"`
+}
+
+var rewardsCmd = &cobra.Command{
+	Use:   "rewards",
+	Short: "Inspects and redeems the rewards of a relayer",
+	Long: `Inspects and redeems the fees a relayer has earned by delivering Teleporter
+messages. Rewards are credited on the source chain of the delivered messages
+once their receipts are received, per fee token. The fee tokens are given by
+--fee-token, or with --all-tokens are discovered from the fee info of the
+SendCrossChainMessage and AddFeeAmount logs of the chain, from --from-block
+to the latest block.`,
+}
+
+var rewardsShowCmd = &cobra.Command{
+	Use:   "show --rpc RPC_URL... --teleporter-address CONTRACT_ADDRESS --relayer ADDRESS [--fee-token TOKEN...]",
+	Short: "Shows the rewards a relayer can redeem",
+	Long: `Calls checkRelayerRewardAmount for the --relayer address and each fee token,
+on each of the --rpc chains.`,
+	Args: cobra.NoArgs,
+	Run:  rewardsShowRun,
+}
+
+var rewardsRedeemCmd = &cobra.Command{
+	Use: "redeem --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"[--private-key KEY | --keystore FILE | --external-signer URL] [--fee-token TOKEN...]",
+	Short: "Redeems the rewards of a relayer",
+	Long: `Calls redeemRelayerRewards for each fee token the signing relayer has a reward
+in, and confirms the RelayerRewardsRedeemed log of each redemption. Fee
+tokens without a reward are skipped.`,
+	Args: cobra.NoArgs,
+	Run:  rewardsRedeemRun,
+}
+
+func rewardsShowRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	relayer := common.HexToAddress(rewardsRelayer)
+	out := relayerRewardsOutput{
+		Relayer: relayer.Hex(),
+		Rewards: []relayerRewardOutput{},
+	}
+	for _, endpoint := range rewardsRPCEndpoints {
+		c, err := ethclient.Dial(endpoint)
+		cobra.CheckErr(err)
+		blockchainID, err := getBlockchainID(ctx, c)
+		cobra.CheckErr(err)
+		messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, c)
+		cobra.CheckErr(err)
+
+		feeTokens, err := getRewardFeeTokens(ctx, c)
+		cobra.CheckErr(err)
+		for _, feeToken := range feeTokens {
+			amount, err := messenger.CheckRelayerRewardAmount(&bind.CallOpts{Context: ctx}, relayer, feeToken)
+			cobra.CheckErr(err)
+			out.Rewards = append(out.Rewards, relayerRewardOutput{
+				BlockchainID:    newBlockchainIDOutput(blockchainID),
+				FeeTokenAddress: feeToken.Hex(),
+				Amount:          bigIntString(amount),
+			})
+		}
+		c.Close()
+	}
+
+	writeOutput(cmd, out)
+	cmd.Println("Rewards show command ran successfully")
+}
+
+func rewardsRedeemRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	signer, err := newSigner(cmd)
+	cobra.CheckErr(err)
+	redeemer := signer.Address()
+	messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, client)
+	cobra.CheckErr(err)
+
+	feeTokens, err := getRewardFeeTokens(ctx, client)
+	cobra.CheckErr(err)
+
+	out := redeemOutput{
+		Redeemer:    redeemer.Hex(),
+		Redemptions: []eventOutput{},
+	}
+	for _, feeToken := range feeTokens {
+		amount, err := messenger.CheckRelayerRewardAmount(&bind.CallOpts{Context: ctx}, redeemer, feeToken)
+		cobra.CheckErr(err)
+		// redeemRelayerRewards reverts if there is no reward to redeem.
+		if amount.Sign() == 0 {
+			logger.Debug("No reward to redeem", zap.String("feeTokenAddress", feeToken.Hex()))
+			continue
+		}
+
+		data, err := teleportermessenger.PackRedeemRelayerRewards(feeToken)
+		cobra.CheckErr(err)
+		receipt, err := sendContractTransaction(ctx, client, signer, teleporterAddress, data)
+		cobra.CheckErr(err)
+
+		event, err := getEventFromLogs(receipt.Logs, messenger.ParseRelayerRewardsRedeemed)
+		cobra.CheckErr(err)
+		if event.Redeemer != redeemer || event.Asset != feeToken || event.Amount.Cmp(amount) != 0 {
+			cobra.CheckErr(fmt.Errorf("unexpected RelayerRewardsRedeemed log in transaction %s: "+
+				"redeemed %s of %s to %s, expected %s of %s to %s",
+				receipt.TxHash.Hex(), event.Amount, event.Asset.Hex(), event.Redeemer.Hex(),
+				amount, feeToken.Hex(), redeemer.Hex()))
+		}
+		out.Redemptions = append(out.Redemptions,
+			newEventOutput(teleportermessenger.RelayerRewardsRedeemed.String(), event, &event.Raw))
+	}
+
+	writeOutput(cmd, out)
+	cmd.Println("Rewards redeem command ran successfully")
+}
+
+// getRewardFeeTokens returns the --fee-token addresses, along with the fee tokens used on the
+// chain if --all-tokens is set
+func getRewardFeeTokens(ctx context.Context, c ethclient.Client) ([]common.Address, error) {
+	var feeTokens []common.Address
+	for _, feeToken := range rewardsFeeTokens {
+		feeTokens = append(feeTokens, common.HexToAddress(feeToken))
+	}
+	if !rewardsAllTokens {
+		return feeTokens, nil
+	}
+	toBlock, err := c.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	discovered, err := discoverFeeTokens(ctx, c, rewardsFromBlock, toBlock, rewardsChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, feeToken := range discovered {
+		if !containsAddress(feeTokens, feeToken) {
+			feeTokens = append(feeTokens, feeToken)
+		}
+	}
+	return feeTokens, nil
+}
+
+// discoverFeeTokens returns the fee tokens of the messages sent from a chain, from the fee info
+// of its SendCrossChainMessage and AddFeeAmount logs between fromBlock and toBlock, sorted by address
+func discoverFeeTokens(
+	ctx context.Context,
+	client logFilterer,
+	fromBlock uint64,
+	toBlock uint64,
+	chunkSize uint64,
+) ([]common.Address, error) {
+	query := interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress},
+		Topics: [][]common.Hash{{
+			teleporterABI.Events[teleportermessenger.SendCrossChainMessage.String()].ID,
+			teleporterABI.Events[teleportermessenger.AddFeeAmount.String()].ID,
+		}},
+	}
+	found := make(map[common.Address]bool)
+	err := filterLogsInChunks(ctx, client, query, fromBlock, toBlock, chunkSize, func(log types.Log) error {
+		_, event, err := parseTeleporterLog(log.Topics, log.Data)
+		if err != nil {
+			logger.Warn("Failed to parse Teleporter log", zap.String("txHash", log.TxHash.Hex()), zap.Error(err))
+			return nil
+		}
+		switch e := event.(type) {
+		case *teleportermessenger.TeleporterMessengerSendCrossChainMessage:
+			found[e.FeeInfo.FeeTokenAddress] = true
+		case *teleportermessenger.TeleporterMessengerAddFeeAmount:
+			found[e.UpdatedFeeInfo.FeeTokenAddress] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Messages sent without a fee have the zero address as their fee token.
+	delete(found, common.Address{})
+
+	feeTokens := make([]common.Address, 0, len(found))
+	for feeToken := range found {
+		feeTokens = append(feeTokens, feeToken)
+	}
+	sort.Slice(feeTokens, func(i, j int) bool {
+		return bytes.Compare(feeTokens[i].Bytes(), feeTokens[j].Bytes()) < 0
+	})
+	return feeTokens, nil
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(rewardsCmd)
+	rewardsCmd.AddCommand(rewardsShowCmd)
+	rewardsCmd.AddCommand(rewardsRedeemCmd)
+	address := rewardsCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	rewardsCmd.PersistentFlags().StringSliceVar(&rewardsFeeTokens, "fee-token", []string{},
+		"Addresses of the ERC20 fee tokens to check")
+	rewardsCmd.PersistentFlags().BoolVar(&rewardsAllTokens, "all-tokens", false,
+		"Discover the fee tokens from the SendCrossChainMessage and AddFeeAmount logs of each chain")
+	rewardsCmd.PersistentFlags().Uint64Var(&rewardsFromBlock, "from-block", 0,
+		"First block to discover fee tokens from with --all-tokens")
+	rewardsCmd.PersistentFlags().Uint64Var(&rewardsChunkSize, "chunk-size", defaultLogChunkSize,
+		"Maximum number of blocks to query logs for in a single request")
+	rewardsCmd.MarkFlagsOneRequired("fee-token", "all-tokens")
+	err := rewardsCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+
+	rewardsShowCmd.Flags().StringSliceVar(&rewardsRPCEndpoints, "rpc", []string{},
+		"RPC endpoints of the chains to check")
+	addChainsFlag(rewardsShowCmd)
+	rewardsShowCmd.Flags().StringVar(&rewardsRelayer, "relayer", "", "Reward address of the relayer")
+	err = rewardsShowCmd.MarkFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = rewardsShowCmd.MarkFlagRequired("relayer")
+	cobra.CheckErr(err)
+	rewardsShowCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Run the persistent pre-run function of the root command if it exists.
+		if err := callPersistentPreRunE(cmd, args); err != nil {
+			return err
+		}
+		if err := applyChainsProfile(cmd, "rpc"); err != nil {
+			return err
+		}
+		teleporterAddress = common.HexToAddress(*address)
+		return nil
+	}
+
+	rewardsRedeemCmd.Flags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint to connect to the node")
+	addChainFlag(rewardsRedeemCmd)
+	addSignerFlags(rewardsRedeemCmd.Flags(), "of the relayer to sign the transactions")
+	err = rewardsRedeemCmd.MarkFlagRequired("rpc")
+	cobra.CheckErr(err)
+	rewardsRedeemCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return rpcPreRunE(cmd, args, address)
+	}
+}
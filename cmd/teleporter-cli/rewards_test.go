@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewardsCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "show extra args",
+			args: []string{"rewards", "show", "extra"},
+			err:  fmt.Errorf("unknown command \"extra\" for \"teleporter-cli rewards show\""),
+		},
+		{
+			name: "show no fee tokens",
+			args: []string{"rewards", "show", "--rpc", "http://127.0.0.1:9650", "--relayer", "0x01", "-t", "0x02"},
+			err:  fmt.Errorf("at least one of the flags in the group [fee-token all-tokens] is required"),
+		},
+		{
+			name: "show help",
+			args: []string{"rewards", "show", "--help"},
+			err:  nil,
+			out:  "Calls checkRelayerRewardAmount for the --relayer address and each fee token",
+		},
+		{
+			name: "redeem help",
+			args: []string{"rewards", "redeem", "--help"},
+			err:  nil,
+			out:  "Calls redeemRelayerRewards for each fee token the signing relayer has a reward",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+// staticFilterer returns the logs in the queried block range, ignoring the rest of the query
+type staticFilterer struct {
+	logs []types.Log
+}
+
+func (f *staticFilterer) FilterLogs(_ context.Context, query interfaces.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for _, log := range f.logs {
+		if log.BlockNumber >= query.FromBlock.Uint64() && log.BlockNumber <= query.ToBlock.Uint64() {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func TestDiscoverFeeTokens(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	tokenA := common.HexToAddress("0x0a")
+	tokenB := common.HexToAddress("0x0b")
+	tokenC := common.HexToAddress("0x0c")
+	destination := common.Hash(ids.ID{1})
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		RequiredGasLimit:        big.NewInt(0),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{},
+	}
+	fee := func(token common.Address) teleportermessenger.TeleporterFeeInfo {
+		return teleportermessenger.TeleporterFeeInfo{FeeTokenAddress: token, Amount: big.NewInt(1)}
+	}
+	atBlock := func(log types.Log, block uint64) types.Log {
+		log.BlockNumber = block
+		return log
+	}
+
+	filterer := &staticFilterer{logs: []types.Log{
+		atBlock(packTeleporterLog(t, "SendCrossChainMessage",
+			[]common.Hash{destination, common.BigToHash(big.NewInt(1))}, message, fee(tokenB)), 1),
+		// Messages sent without a fee are skipped
+		atBlock(packTeleporterLog(t, "SendCrossChainMessage",
+			[]common.Hash{destination, common.BigToHash(big.NewInt(2))}, message, fee(common.Address{})), 2),
+		atBlock(packTeleporterLog(t, "AddFeeAmount",
+			[]common.Hash{destination, common.BigToHash(big.NewInt(0))}, fee(tokenA)), 3),
+		atBlock(packTeleporterLog(t, "SendCrossChainMessage",
+			[]common.Hash{destination, common.BigToHash(big.NewInt(3))}, message, fee(tokenB)), 4),
+		// Logs after the range are skipped
+		atBlock(packTeleporterLog(t, "SendCrossChainMessage",
+			[]common.Hash{destination, common.BigToHash(big.NewInt(4))}, message, fee(tokenC)), 10),
+	}}
+
+	feeTokens, err := discoverFeeTokens(context.Background(), filterer, 0, 5, 2)
+	require.NoError(t, err)
+	require.Equal(t, []common.Address{tokenA, tokenB}, feeTokens)
+}
@@ -37,8 +37,23 @@ func Execute() {
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	logLevelArg := rootCmd.PersistentFlags().StringP("log", "l", "", "Log level i.e. debug, info...")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", textFormat,
+		"Output format of command results, one of text, json, yaml, table")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "",
+		"Config file defining named chains. Defaults to ~/"+defaultConfigFileName)
+	rootCmd.PersistentFlags().StringSliceVar(&payloadABIFiles, "payload-abi", []string{},
+		"JSON or YAML files of application payload decoders keyed by receiver address")
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		return rootPreRunE(logLevelArg)
+		if err := rootPreRunE(logLevelArg); err != nil {
+			return err
+		}
+		decoders, err := readPayloadDecoderFiles(cmd, payloadABIFiles)
+		if err != nil {
+			return err
+		}
+		flagPayloadDecoders = decoders
+		payloadDecoders = nil
+		return nil
 	}
 }
 
@@ -51,11 +66,15 @@ func rootPreRunE(logLevelArg *string) error {
 	if err != nil {
 		return err
 	}
+	if err := validateOutputFormat(outputFormat); err != nil {
+		return err
+	}
+	// Logs are written to stderr, so that stdout only contains command results.
 	logger = logging.NewLogger(
 		"teleporter-cli",
 		logging.NewWrappedCore(
 			logLevel,
-			os.Stdout,
+			os.Stderr,
 			logging.Plain.ConsoleEncoder(),
 		),
 	)
@@ -67,8 +86,18 @@ func rootPreRunE(logLevelArg *string) error {
 	return nil
 }
 
+// callPersistentPreRunE runs the next persistent pre-run function up the command tree from the one
+// cobra runs for cmd, which is that of the closest command to cmd that has one, since cobra only
+// runs that one.
 func callPersistentPreRunE(cmd *cobra.Command, args []string) error {
-	if parent := cmd.Parent(); parent != nil {
+	owner := cmd
+	for owner != nil && owner.PersistentPreRunE == nil {
+		owner = owner.Parent()
+	}
+	if owner == nil {
+		return nil
+	}
+	for parent := owner.Parent(); parent != nil; parent = parent.Parent() {
 		if parent.PersistentPreRunE != nil {
 			return parent.PersistentPreRunE(parent, args)
 		}
@@ -58,3 +58,30 @@ func TestRootCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestCallPersistentPreRunE(t *testing.T) {
+	var calls []string
+	hook := func(name string) func(*cobra.Command, []string) error {
+		return func(cmd *cobra.Command, args []string) error {
+			calls = append(calls, name+":"+cmd.Name())
+			return callPersistentPreRunE(cmd, args)
+		}
+	}
+	root := &cobra.Command{Use: "root", PersistentPreRunE: hook("root")}
+	parent := &cobra.Command{Use: "parent", PersistentPreRunE: hook("parent")}
+	middle := &cobra.Command{Use: "middle"}
+	leaf := &cobra.Command{Use: "leaf", Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(parent)
+	parent.AddCommand(middle)
+	middle.AddCommand(leaf)
+
+	_, err := executeTestCmd(t, root, "parent", "middle", "leaf")
+	require.NoError(t, err)
+	require.Equal(t, []string{"parent:leaf", "root:root"}, calls)
+
+	calls = nil
+	leaf.PersistentPreRunE = hook("leaf")
+	_, err = executeTestCmd(t, root, "parent", "middle", "leaf")
+	require.NoError(t, err)
+	require.Equal(t, []string{"leaf:leaf", "parent:parent", "root:root"}, calls)
+}
@@ -0,0 +1,306 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/x/warp"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	scanFromBlock uint64
+	scanToBlock   uint64
+	scanSince     time.Duration
+	scanChunkSize uint64
+)
+
+type routeCountOutput struct {
+	BlockchainID blockchainIDOutput `json:"blockchainID"`
+	Count        int                `json:"count"`
+}
+
+type failedExecutionOutput struct {
+	OriginBlockchainID blockchainIDOutput `json:"originBlockchainID"`
+	MessageID          string             `json:"messageID"`
+	BlockNumber        uint64             `json:"blockNumber"`
+	TxHash             string             `json:"txHash"`
+}
+
+type feeTotalOutput struct {
+	FeeTokenAddress string `json:"feeTokenAddress"`
+	Messages        int    `json:"messages"`
+	SentAmount      string `json:"sentAmount"`
+	Additions       int    `json:"additions"`
+	AddedAmount     string `json:"addedAmount"`
+}
+
+type rewardTotalOutput struct {
+	Asset       string `json:"asset"`
+	Redemptions int    `json:"redemptions"`
+	Amount      string `json:"amount"`
+}
+
+type scanOutput struct {
+	BlockchainID     blockchainIDOutput      `json:"blockchainID"`
+	FromBlock        uint64                  `json:"fromBlock"`
+	ToBlock          uint64                  `json:"toBlock"`
+	SentMessages     []routeCountOutput      `json:"sentMessages"`
+	RetriedSends     int                     `json:"retriedSends"`
+	ReceivedMessages []routeCountOutput      `json:"receivedMessages"`
+	WarpMessages     int                     `json:"warpMessages"`
+	ExecutedMessages int                     `json:"executedMessages"`
+	FailedExecutions []failedExecutionOutput `json:"failedExecutions"`
+	Fees             []feeTotalOutput        `json:"fees"`
+	RewardsRedeemed  []rewardTotalOutput     `json:"rewardsRedeemed"`
+}
+
+type feeTotal struct {
+	messages    int
+	sentAmount  *big.Int
+	additions   int
+	addedAmount *big.Int
+}
+
+type rewardTotal struct {
+	redemptions int
+	amount      *big.Int
+}
+
+type messageKey struct {
+	destinationBlockchainID ids.ID
+	messageID               string
+}
+
+// scanReport aggregates the Teleporter activity of a single chain
+type scanReport struct {
+	sent             map[ids.ID]int
+	retriedSends     int
+	received         map[ids.ID]int
+	warpMessages     int
+	executed         int
+	failedExecutions []failedExecutionOutput
+	fees             map[common.Address]*feeTotal
+	rewards          map[common.Address]*rewardTotal
+	// latestFees holds the latest fee amount seen for each message, to compute the amount added
+	// by AddFeeAmount, which only logs the updated total
+	latestFees map[messageKey]*big.Int
+}
+
+func newScanReport() *scanReport {
+	return &scanReport{
+		sent:       make(map[ids.ID]int),
+		received:   make(map[ids.ID]int),
+		fees:       make(map[common.Address]*feeTotal),
+		rewards:    make(map[common.Address]*rewardTotal),
+		latestFees: make(map[messageKey]*big.Int),
+	}
+}
+
+var scanCmd = &cobra.Command{
+	Use: "scan --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"[--from-block BLOCK [--to-block BLOCK] | --since DURATION]",
+	Short: "Summarizes all Teleporter activity of a chain over a block range",
+	Long: `Pages through the Teleporter and Warp logs of a chain over a block range, and
+prints an aggregated report: messages sent per destination, messages
+received per origin, Teleporter Warp messages, executed messages, failed
+executions, fees paid and added per fee token, and relayer rewards redeemed
+per asset. The range is either --from-block to --to-block, which defaults to
+the latest block, or the blocks produced within --since of now. Large ranges
+are queried in chunks of --chunk-size blocks.
+
+Fees added by AddFeeAmount are computed from the previous fee of the message,
+so additions to messages sent before the range count towards the number of
+additions but not the added amount. Sends retried by retrySendCrossChainMessage
+log the message again, and are counted as retried sends rather than as new
+messages once the message was seen in the range.`,
+	Args: cobra.NoArgs,
+	Run:  scanRun,
+}
+
+func scanRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	blockchainID, err := getBlockchainID(ctx, client)
+	cobra.CheckErr(err)
+
+	fromBlock, toBlock, err := getBlockRange(ctx, cmd, client, scanFromBlock, scanToBlock, scanSince)
+	cobra.CheckErr(err)
+	logger.Debug("Scanning Teleporter logs",
+		zap.Uint64("fromBlock", fromBlock),
+		zap.Uint64("toBlock", toBlock))
+
+	report := newScanReport()
+	query := interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress, warp.ContractAddress},
+	}
+	err = filterLogsInChunks(ctx, client, query, fromBlock, toBlock, scanChunkSize, func(log types.Log) error {
+		report.addLog(log, blockchainID)
+		return nil
+	})
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, report.output(blockchainID, fromBlock, toBlock))
+	cmd.Println("Scan command ran successfully")
+}
+
+// addLog decodes a Teleporter or Warp log emitted on the chain with the given blockchain ID
+// and adds it to the report. Logs that fail to decode are skipped.
+func (r *scanReport) addLog(log types.Log, blockchainID ids.ID) {
+	switch log.Address {
+	case warp.ContractAddress:
+		if isTeleporterWarpLog(&log) {
+			r.warpMessages++
+		}
+		return
+	case teleporterAddress:
+	default:
+		return
+	}
+
+	_, event, err := parseTeleporterLog(log.Topics, log.Data)
+	if err != nil {
+		logger.Warn("Failed to parse Teleporter log", zap.String("txHash", log.TxHash.Hex()), zap.Error(err))
+		return
+	}
+	switch e := event.(type) {
+	case *teleportermessenger.TeleporterMessengerSendCrossChainMessage:
+		// retrySendCrossChainMessage logs the message again with its current fee, so only the first
+		// log of a message is a new message.
+		key := messageKey{e.DestinationBlockchainID, e.MessageID.String()}
+		if _, ok := r.latestFees[key]; ok {
+			r.retriedSends++
+			return
+		}
+		r.sent[e.DestinationBlockchainID]++
+		fee := r.feeTotal(e.FeeInfo.FeeTokenAddress)
+		fee.messages++
+		fee.sentAmount.Add(fee.sentAmount, e.FeeInfo.Amount)
+		r.latestFees[key] = e.FeeInfo.Amount
+	case *teleportermessenger.TeleporterMessengerAddFeeAmount:
+		fee := r.feeTotal(e.UpdatedFeeInfo.FeeTokenAddress)
+		fee.additions++
+		key := messageKey{e.DestinationBlockchainID, e.MessageID.String()}
+		if previous, ok := r.latestFees[key]; ok {
+			fee.addedAmount.Add(fee.addedAmount, new(big.Int).Sub(e.UpdatedFeeInfo.Amount, previous))
+		}
+		r.latestFees[key] = e.UpdatedFeeInfo.Amount
+	case *teleportermessenger.TeleporterMessengerReceiveCrossChainMessage:
+		r.received[e.OriginBlockchainID]++
+	case *teleportermessenger.TeleporterMessengerMessageExecuted:
+		r.executed++
+	case *teleportermessenger.TeleporterMessengerMessageExecutionFailed:
+		r.failedExecutions = append(r.failedExecutions, failedExecutionOutput{
+			OriginBlockchainID: newBlockchainIDOutput(e.OriginBlockchainID),
+			MessageID:          bigIntString(e.MessageID),
+			BlockNumber:        log.BlockNumber,
+			TxHash:             log.TxHash.Hex(),
+		})
+	case *teleportermessenger.TeleporterMessengerRelayerRewardsRedeemed:
+		reward, ok := r.rewards[e.Asset]
+		if !ok {
+			reward = &rewardTotal{amount: big.NewInt(0)}
+			r.rewards[e.Asset] = reward
+		}
+		reward.redemptions++
+		reward.amount.Add(reward.amount, e.Amount)
+	}
+}
+
+func (r *scanReport) feeTotal(feeTokenAddress common.Address) *feeTotal {
+	fee, ok := r.fees[feeTokenAddress]
+	if !ok {
+		fee = &feeTotal{sentAmount: big.NewInt(0), addedAmount: big.NewInt(0)}
+		r.fees[feeTokenAddress] = fee
+	}
+	return fee
+}
+
+// output converts the report to its output schema, sorted for stable output
+func (r *scanReport) output(blockchainID ids.ID, fromBlock uint64, toBlock uint64) scanOutput {
+	out := scanOutput{
+		BlockchainID:     newBlockchainIDOutput(blockchainID),
+		FromBlock:        fromBlock,
+		ToBlock:          toBlock,
+		SentMessages:     newRouteCountsOutput(r.sent),
+		RetriedSends:     r.retriedSends,
+		ReceivedMessages: newRouteCountsOutput(r.received),
+		WarpMessages:     r.warpMessages,
+		ExecutedMessages: r.executed,
+		FailedExecutions: r.failedExecutions,
+		Fees:             []feeTotalOutput{},
+		RewardsRedeemed:  []rewardTotalOutput{},
+	}
+	if out.FailedExecutions == nil {
+		out.FailedExecutions = []failedExecutionOutput{}
+	}
+	for feeTokenAddress, fee := range r.fees {
+		out.Fees = append(out.Fees, feeTotalOutput{
+			FeeTokenAddress: feeTokenAddress.Hex(),
+			Messages:        fee.messages,
+			SentAmount:      fee.sentAmount.String(),
+			Additions:       fee.additions,
+			AddedAmount:     fee.addedAmount.String(),
+		})
+	}
+	sort.Slice(out.Fees, func(i, j int) bool {
+		return out.Fees[i].FeeTokenAddress < out.Fees[j].FeeTokenAddress
+	})
+	for asset, reward := range r.rewards {
+		out.RewardsRedeemed = append(out.RewardsRedeemed, rewardTotalOutput{
+			Asset:       asset.Hex(),
+			Redemptions: reward.redemptions,
+			Amount:      reward.amount.String(),
+		})
+	}
+	sort.Slice(out.RewardsRedeemed, func(i, j int) bool {
+		return out.RewardsRedeemed[i].Asset < out.RewardsRedeemed[j].Asset
+	})
+	return out
+}
+
+func newRouteCountsOutput(counts map[ids.ID]int) []routeCountOutput {
+	out := []routeCountOutput{}
+	for blockchainID, count := range counts {
+		out = append(out, routeCountOutput{
+			BlockchainID: newBlockchainIDOutput(blockchainID),
+			Count:        count,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].BlockchainID.Hex < out[j].BlockchainID.Hex
+	})
+	return out
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+	scanCmd.PersistentFlags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint to connect to the node")
+	address := scanCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addChainFlag(scanCmd)
+	scanCmd.Flags().Uint64Var(&scanFromBlock, "from-block", 0, "First block of the range to scan")
+	scanCmd.Flags().Uint64Var(&scanToBlock, "to-block", 0, "Last block of the range to scan. Defaults to the latest block")
+	scanCmd.Flags().DurationVar(&scanSince, "since", 0, "Scan the blocks produced within this duration of now, i.e. 1h")
+	scanCmd.Flags().Uint64Var(&scanChunkSize, "chunk-size", defaultLogChunkSize,
+		"Maximum number of blocks to query logs for in a single request")
+	scanCmd.MarkFlagsMutuallyExclusive("from-block", "since")
+	scanCmd.MarkFlagsOneRequired("from-block", "since")
+	err := scanCmd.MarkPersistentFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = scanCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	scanCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return rpcPreRunE(cmd, args, address)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "extra args",
+			args: []string{"scan", "extra"},
+			err:  fmt.Errorf("unknown command \"extra\" for \"teleporter-cli scan\""),
+		},
+		{
+			name: "help",
+			args: []string{"scan", "--help"},
+			err:  nil,
+			out:  "Pages through the Teleporter and Warp logs of a chain over a block range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+// rangeLimitedFilterer returns one log per block, and fails queries spanning more than maxRange blocks
+type rangeLimitedFilterer struct {
+	maxRange uint64
+	queries  [][2]uint64
+}
+
+func (f *rangeLimitedFilterer) FilterLogs(_ context.Context, query interfaces.FilterQuery) ([]types.Log, error) {
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	f.queries = append(f.queries, [2]uint64{from, to})
+	if to-from+1 > f.maxRange {
+		return nil, errors.New("block range too large")
+	}
+	var logs []types.Log
+	for block := from; block <= to; block++ {
+		logs = append(logs, types.Log{BlockNumber: block})
+	}
+	return logs, nil
+}
+
+func TestFilterLogsInChunks(t *testing.T) {
+	var tests = []struct {
+		name      string
+		maxRange  uint64
+		chunkSize uint64
+		queries   [][2]uint64
+		err       bool
+	}{
+		{
+			name:      "chunked",
+			maxRange:  10,
+			chunkSize: 4,
+			queries:   [][2]uint64{{5, 8}, {9, 12}, {13, 14}},
+		},
+		{
+			name:      "halved",
+			maxRange:  3,
+			chunkSize: 8,
+			queries:   [][2]uint64{{5, 12}, {5, 8}, {5, 6}, {7, 8}, {9, 10}, {11, 12}, {13, 14}},
+		},
+		{
+			name:      "fails",
+			maxRange:  0,
+			chunkSize: 2,
+			queries:   [][2]uint64{{5, 6}, {5, 5}},
+			err:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filterer := &rangeLimitedFilterer{maxRange: tt.maxRange}
+			var blocks []uint64
+			err := filterLogsInChunks(context.Background(), filterer, interfaces.FilterQuery{}, 5, 14, tt.chunkSize,
+				func(log types.Log) error {
+					blocks = append(blocks, log.BlockNumber)
+					return nil
+				})
+			require.Equal(t, tt.queries, filterer.queries)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []uint64{5, 6, 7, 8, 9, 10, 11, 12, 13, 14}, blocks)
+		})
+	}
+}
+
+// packTeleporterLog builds a log of the named Teleporter event from its indexed and non-indexed arguments
+func packTeleporterLog(t *testing.T, name string, indexed []common.Hash, args ...interface{}) types.Log {
+	event := teleporterABI.Events[name]
+	data, err := event.Inputs.NonIndexed().Pack(args...)
+	require.NoError(t, err)
+	return types.Log{
+		Address: teleporterAddress,
+		Topics:  append([]common.Hash{event.ID}, indexed...),
+		Data:    data,
+	}
+}
+
+func TestScanReport(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	chainA := ids.ID{1}
+	chainB := ids.ID{2}
+	chainC := ids.ID{3}
+	feeToken := common.HexToAddress("0x01")
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		DestinationBlockchainID: chainB,
+		RequiredGasLimit:        big.NewInt(0),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{},
+	}
+	messageIDTopic := func(id int64) common.Hash {
+		return common.BigToHash(big.NewInt(id))
+	}
+	fee := func(amount int64) teleportermessenger.TeleporterFeeInfo {
+		return teleportermessenger.TeleporterFeeInfo{FeeTokenAddress: feeToken, Amount: big.NewInt(amount)}
+	}
+
+	logs := []types.Log{
+		packTeleporterLog(t, "SendCrossChainMessage",
+			[]common.Hash{common.Hash(chainB), messageIDTopic(1)}, message, fee(10)),
+		packTeleporterLog(t, "SendCrossChainMessage",
+			[]common.Hash{common.Hash(chainB), messageIDTopic(2)}, message, fee(5)),
+		packTeleporterLog(t, "SendCrossChainMessage",
+			[]common.Hash{common.Hash(chainC), messageIDTopic(1)}, message, fee(0)),
+		// Adds 15 to a message sent in the range, and an unknown amount to one sent before it
+		packTeleporterLog(t, "AddFeeAmount",
+			[]common.Hash{common.Hash(chainB), messageIDTopic(1)}, fee(25)),
+		packTeleporterLog(t, "AddFeeAmount",
+			[]common.Hash{common.Hash(chainB), messageIDTopic(0)}, fee(100)),
+		// A retried send logs the message again with its current fee, and is not a new message
+		packTeleporterLog(t, "SendCrossChainMessage",
+			[]common.Hash{common.Hash(chainB), messageIDTopic(1)}, message, fee(25)),
+		packTeleporterLog(t, "AddFeeAmount",
+			[]common.Hash{common.Hash(chainB), messageIDTopic(1)}, fee(30)),
+		packTeleporterLog(t, "ReceiveCrossChainMessage",
+			[]common.Hash{common.Hash(chainC), messageIDTopic(4), common.BytesToHash(feeToken.Bytes())},
+			common.Address{}, message),
+		packTeleporterLog(t, "MessageExecuted",
+			[]common.Hash{common.Hash(chainC), messageIDTopic(4)}),
+		packTeleporterLog(t, "MessageExecutionFailed",
+			[]common.Hash{common.Hash(chainC), messageIDTopic(5)}, message),
+		packTeleporterLog(t, "RelayerRewardsRedeemed",
+			[]common.Hash{common.BytesToHash(feeToken.Bytes()), common.BytesToHash(feeToken.Bytes())},
+			big.NewInt(7)),
+		// Logs of other contracts are ignored
+		{Address: common.HexToAddress("0x02"), Topics: []common.Hash{{}}},
+	}
+
+	report := newScanReport()
+	for _, log := range logs {
+		report.addLog(log, chainA)
+	}
+	out := report.output(chainA, 1, 2)
+
+	require.Equal(t, []routeCountOutput{
+		{BlockchainID: newBlockchainIDOutput(chainB), Count: 2},
+		{BlockchainID: newBlockchainIDOutput(chainC), Count: 1},
+	}, out.SentMessages)
+	require.Equal(t, 1, out.RetriedSends)
+	require.Equal(t, []routeCountOutput{
+		{BlockchainID: newBlockchainIDOutput(chainC), Count: 1},
+	}, out.ReceivedMessages)
+	require.Equal(t, 1, out.ExecutedMessages)
+	require.Len(t, out.FailedExecutions, 1)
+	require.Equal(t, "5", out.FailedExecutions[0].MessageID)
+	require.Equal(t, []feeTotalOutput{{
+		FeeTokenAddress: feeToken.Hex(),
+		Messages:        3,
+		SentAmount:      "15",
+		Additions:       3,
+		AddedAmount:     "20",
+	}}, out.Fees)
+	require.Equal(t, []rewardTotalOutput{{
+		Asset:       feeToken.Hex(),
+		Redemptions: 1,
+		Amount:      "7",
+	}}, out.RewardsRedeemed)
+}
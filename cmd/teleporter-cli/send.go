@@ -0,0 +1,198 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	sendInputFile               string
+	sendDestinationBlockchainID string
+	sendDestinationAddress      string
+	sendFeeTokenAddress         string
+	sendFeeAmount               string
+	sendRequiredGasLimit        uint64
+	sendAllowedRelayers         []string
+	sendPayload                 []byte
+	sendPayloadFile             string
+
+	errMissingDestinationBlockchainID = errors.New("destination blockchain ID is required")
+)
+
+// sendInputJSON is the JSON file representation of a TeleporterMessageInput
+type sendInputJSON struct {
+	DestinationBlockchainID string         `json:"destinationBlockchainID"`
+	DestinationAddress      common.Address `json:"destinationAddress"`
+	FeeInfo                 struct {
+		FeeTokenAddress common.Address `json:"feeTokenAddress"`
+		Amount          *big.Int       `json:"amount"`
+	} `json:"feeInfo"`
+	RequiredGasLimit        *big.Int         `json:"requiredGasLimit"`
+	AllowedRelayerAddresses []common.Address `json:"allowedRelayerAddresses"`
+	Message                 hexutil.Bytes    `json:"message"`
+}
+
+var sendCmd = &cobra.Command{
+	Use: "send --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"[--private-key KEY | --keystore FILE | --external-signer URL] " +
+		"[--input INPUT_FILE] [--destination-blockchain-id ID] [flags]",
+	Short: "Sends a Teleporter message with sendCrossChainMessage",
+	Long: `Builds a TeleporterMessageInput from flags and/or a JSON or YAML input
+file, then signs and submits a sendCrossChainMessage transaction to the
+Teleporter contract. Flags take precedence over the values in the input file.
+The destination blockchain ID may be given in cb58 or as 0x prefixed hex. If a
+fee is set, the Teleporter contract is first approved to spend the fee token.
+Prints the ID of the sent message from the SendCrossChainMessage log.`,
+	Args: cobra.NoArgs,
+	Run:  sendRun,
+}
+
+func sendRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	signer, err := newSigner(cmd)
+	cobra.CheckErr(err)
+
+	input, err := buildSendInput(cmd)
+	cobra.CheckErr(err)
+	logger.Debug("Built TeleporterMessageInput", zap.Any("input", input))
+
+	err = approveERC20(ctx, client, signer, input.FeeInfo.FeeTokenAddress, teleporterAddress, input.FeeInfo.Amount)
+	cobra.CheckErr(err)
+
+	data, err := teleportermessenger.PackSendCrossChainMessage(input)
+	cobra.CheckErr(err)
+	receipt, err := sendContractTransaction(ctx, client, signer, teleporterAddress, data)
+	cobra.CheckErr(err)
+
+	messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, client)
+	cobra.CheckErr(err)
+	event, err := getEventFromLogs(receipt.Logs, messenger.ParseSendCrossChainMessage)
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, newEventOutput(teleportermessenger.SendCrossChainMessage.String(), event, &event.Raw))
+	cmd.Println("Send command ran successfully")
+}
+
+// buildSendInput constructs the TeleporterMessageInput from the input file, if any,
+// overriding its values with any flags that were explicitly set.
+func buildSendInput(cmd *cobra.Command) (teleportermessenger.TeleporterMessageInput, error) {
+	var fileInput sendInputJSON
+	if sendInputFile != "" {
+		b, err := os.ReadFile(sendInputFile)
+		if err != nil {
+			return teleportermessenger.TeleporterMessageInput{}, err
+		}
+		if err := unmarshalInput(b, &fileInput); err != nil {
+			return teleportermessenger.TeleporterMessageInput{},
+				fmt.Errorf("failed to unmarshal input file: %w", err)
+		}
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("destination-blockchain-id") {
+		fileInput.DestinationBlockchainID = sendDestinationBlockchainID
+	}
+	if fileInput.DestinationBlockchainID == "" {
+		return teleportermessenger.TeleporterMessageInput{}, errMissingDestinationBlockchainID
+	}
+	destinationBlockchainID, err := resolveBlockchainID(fileInput.DestinationBlockchainID)
+	if err != nil {
+		return teleportermessenger.TeleporterMessageInput{}, err
+	}
+
+	input := teleportermessenger.TeleporterMessageInput{
+		DestinationBlockchainID: destinationBlockchainID,
+		DestinationAddress:      fileInput.DestinationAddress,
+		FeeInfo: teleportermessenger.TeleporterFeeInfo{
+			FeeTokenAddress: fileInput.FeeInfo.FeeTokenAddress,
+			Amount:          fileInput.FeeInfo.Amount,
+		},
+		RequiredGasLimit:        fileInput.RequiredGasLimit,
+		AllowedRelayerAddresses: fileInput.AllowedRelayerAddresses,
+		Message:                 fileInput.Message,
+	}
+
+	if flags.Changed("destination-address") {
+		input.DestinationAddress = common.HexToAddress(sendDestinationAddress)
+	}
+	if flags.Changed("fee-token") {
+		input.FeeInfo.FeeTokenAddress = common.HexToAddress(sendFeeTokenAddress)
+	}
+	if flags.Changed("fee-amount") {
+		amount, ok := new(big.Int).SetString(sendFeeAmount, 0)
+		if !ok {
+			return teleportermessenger.TeleporterMessageInput{}, fmt.Errorf("invalid fee amount %s", sendFeeAmount)
+		}
+		input.FeeInfo.Amount = amount
+	}
+	if input.FeeInfo.Amount == nil {
+		input.FeeInfo.Amount = big.NewInt(0)
+	}
+	if flags.Changed("required-gas-limit") || input.RequiredGasLimit == nil {
+		input.RequiredGasLimit = new(big.Int).SetUint64(sendRequiredGasLimit)
+	}
+	if flags.Changed("allowed-relayers") {
+		input.AllowedRelayerAddresses = nil
+		for _, relayer := range sendAllowedRelayers {
+			input.AllowedRelayerAddresses = append(input.AllowedRelayerAddresses, common.HexToAddress(relayer))
+		}
+	}
+	if input.AllowedRelayerAddresses == nil {
+		input.AllowedRelayerAddresses = []common.Address{}
+	}
+	if flags.Changed("payload") {
+		input.Message = sendPayload
+	}
+	if sendPayloadFile != "" {
+		input.Message, err = os.ReadFile(sendPayloadFile)
+		if err != nil {
+			return teleportermessenger.TeleporterMessageInput{}, err
+		}
+	}
+	if input.Message == nil {
+		input.Message = []byte{}
+	}
+	return input, nil
+}
+
+func init() {
+	rootCmd.AddCommand(sendCmd)
+	sendCmd.PersistentFlags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint to connect to the node")
+	address := sendCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addChainFlag(sendCmd)
+	addSignerFlags(sendCmd.Flags(), "to sign the transaction")
+	sendCmd.Flags().StringVar(&sendInputFile, "input", "", "JSON or YAML file containing the TeleporterMessageInput")
+	sendCmd.Flags().StringVar(&sendDestinationBlockchainID, "destination-blockchain-id", "",
+		"Destination blockchain ID, in cb58 or hex, or the name of a chain in the config file")
+	sendCmd.Flags().StringVar(&sendDestinationAddress, "destination-address", "",
+		"Address of the message recipient on the destination chain")
+	sendCmd.Flags().StringVar(&sendFeeTokenAddress, "fee-token", "", "Address of the ERC20 fee token")
+	sendCmd.Flags().StringVar(&sendFeeAmount, "fee-amount", "0", "Amount of the fee token to pay the relayer")
+	sendCmd.Flags().Uint64Var(&sendRequiredGasLimit, "required-gas-limit", 0,
+		"Gas limit required to execute the message on the destination chain")
+	sendCmd.Flags().StringSliceVar(&sendAllowedRelayers, "allowed-relayers", []string{},
+		"Addresses of the relayers allowed to deliver the message. Any relayer is allowed if empty")
+	sendCmd.Flags().BytesHexVar(&sendPayload, "payload", []byte{}, "Hex encoded message payload")
+	sendCmd.Flags().StringVar(&sendPayloadFile, "payload-file", "", "File containing the raw message payload")
+	sendCmd.MarkFlagsMutuallyExclusive("payload", "payload-file")
+	err := sendCmd.MarkPersistentFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = sendCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	sendCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return rpcPreRunE(cmd, args, address)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	exampleerc20 "github.com/ava-labs/teleporter/abi-bindings/go/Mocks/ExampleERC20"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "extra args",
+			args: []string{"send", "extra"},
+			err:  fmt.Errorf("unknown command \"extra\" for \"teleporter-cli send\""),
+		},
+		{
+			name: "help",
+			args: []string{"send", "--help"},
+			err:  nil,
+			out:  "Builds a TeleporterMessageInput from flags and/or a JSON or YAML input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+// staticAllowance is an ERC20 token with a single allowance of spender to transfer from owner
+type staticAllowance struct {
+	owner     common.Address
+	spender   common.Address
+	allowance *big.Int
+	err       error
+}
+
+func (a *staticAllowance) Allowance(_ *bind.CallOpts, owner common.Address, spender common.Address) (*big.Int, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	if owner != a.owner || spender != a.spender {
+		return big.NewInt(0), nil
+	}
+	return a.allowance, nil
+}
+
+func TestBuildSendInput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	destinationBlockchainID := ids.ID{1}
+	destinationAddress := common.HexToAddress("0x01")
+	relayer := common.HexToAddress("0x02")
+
+	// The input file may be YAML, with byte arrays and addresses left unquoted
+	sendInputFile = filepath.Join(t.TempDir(), "input.yaml")
+	defer func() { sendInputFile = "" }()
+	input := fmt.Sprintf(`destinationBlockchainID: 0x%s
+destinationAddress: %s
+feeInfo:
+  amount: 10
+requiredGasLimit: 100000
+allowedRelayerAddresses:
+  - %s
+message: 0x0102
+`, destinationBlockchainID.Hex(), destinationAddress.Hex(), relayer.Hex())
+	require.NoError(t, os.WriteFile(sendInputFile, []byte(input), 0o600))
+
+	built, err := buildSendInput(sendCmd)
+	require.NoError(t, err)
+	require.Equal(t, teleportermessenger.TeleporterMessageInput{
+		DestinationBlockchainID: destinationBlockchainID,
+		DestinationAddress:      destinationAddress,
+		FeeInfo: teleportermessenger.TeleporterFeeInfo{
+			Amount: big.NewInt(10),
+		},
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{relayer},
+		Message:                 []byte{0x01, 0x02},
+	}, built)
+}
+
+func TestPackERC20Approval(t *testing.T) {
+	logger = logging.NoLog{}
+	owner := common.HexToAddress("0x01")
+	teleporter := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	erc20ABI, err := exampleerc20.ExampleERC20MetaData.GetAbi()
+	require.NoError(t, err)
+	errAllowance := errors.New("allowance unavailable")
+
+	var tests = []struct {
+		name         string
+		allowance    *big.Int
+		allowanceErr error
+		spender      common.Address
+		amount       *big.Int
+		err          error
+		approve      bool
+	}{
+		{
+			name:    "no fee",
+			spender: teleporter,
+			amount:  big.NewInt(0),
+			// The allowance is not queried, since the fee token is unset without a fee.
+			allowanceErr: errAllowance,
+		},
+		{
+			name:      "sufficient allowance",
+			allowance: big.NewInt(10),
+			spender:   teleporter,
+			amount:    big.NewInt(10),
+		},
+		{
+			name:      "insufficient allowance",
+			allowance: big.NewInt(9),
+			spender:   teleporter,
+			amount:    big.NewInt(10),
+			approve:   true,
+		},
+		{
+			name:      "allowance of another spender",
+			allowance: big.NewInt(10),
+			spender:   common.HexToAddress("0x02"),
+			amount:    big.NewInt(10),
+			approve:   true,
+		},
+		{
+			name:         "allowance error",
+			allowanceErr: errAllowance,
+			spender:      teleporter,
+			amount:       big.NewInt(10),
+			err:          errAllowance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &staticAllowance{owner: owner, spender: tt.spender, allowance: tt.allowance, err: tt.allowanceErr}
+			data, err := packERC20Approval(context.Background(), token, owner, teleporter, tt.amount)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			if !tt.approve {
+				require.Nil(t, data)
+				return
+			}
+			method, err := erc20ABI.MethodById(data[:4])
+			require.NoError(t, err)
+			require.Equal(t, "approve", method.Name)
+			args, err := method.Inputs.Unpack(data[4:])
+			require.NoError(t, err)
+			require.Equal(t, []interface{}{teleporter, tt.amount}, args)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts"
+	"github.com/ava-labs/subnet-evm/accounts/external"
+	"github.com/ava-labs/subnet-evm/accounts/keystore"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// privateKeyEnvVar is read for the hex encoded private key when no other signer is set, i.e. in CI
+const privateKeyEnvVar = "TELEPORTER_PRIVATE_KEY"
+
+var (
+	keystoreFile      string
+	passphraseFile    string
+	externalSignerURL string
+	signerAddress     string
+
+	errMissingSigner = fmt.Errorf(
+		"one of --private-key, --keystore or --external-signer is required, or set %s", privateKeyEnvVar)
+	errMultipleSigners      = errors.New("only one of --private-key, --keystore and --external-signer can be set")
+	errMissingSignerAccount = errors.New("--signer-address is required when the external signer has several accounts")
+)
+
+// txSigner signs transactions on behalf of a single account
+type txSigner interface {
+	Address() common.Address
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// keySigner signs transactions with a local private key
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *keySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *keySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// remoteSigner signs transactions with an account of a clef compatible external signer, which is
+// called over JSON-RPC with account_signTransaction
+type remoteSigner struct {
+	signer  *external.ExternalSigner
+	account accounts.Account
+}
+
+func (s *remoteSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *remoteSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signedTx, err := s.signer.SignTx(s.account, tx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("external signer failed to sign transaction: %w", err)
+	}
+	// The external signer returns the transaction it signed, which must be the one that was requested.
+	chainSigner := types.LatestSignerForChainID(chainID)
+	if chainSigner.Hash(signedTx) != chainSigner.Hash(tx) {
+		return nil, fmt.Errorf("external signer returned a different transaction %s", signedTx.Hash().Hex())
+	}
+	sender, err := types.Sender(chainSigner, signedTx)
+	if err != nil {
+		return nil, err
+	}
+	if sender != s.account.Address {
+		return nil, fmt.Errorf("external signer signed with %s instead of %s", sender.Hex(), s.account.Address.Hex())
+	}
+	return signedTx, nil
+}
+
+// addSignerFlags adds the flags selecting the signer of the transactions sent by a command.
+// keyUsage describes what the key is used for.
+func addSignerFlags(flags *pflag.FlagSet, keyUsage string) {
+	flags.StringVar(&privateKeyHex, "private-key", "", "Hex encoded private key "+keyUsage)
+	flags.StringVar(&keystoreFile, "keystore", "", "Encrypted keystore file of the key "+keyUsage)
+	flags.StringVar(&passphraseFile, "passphrase-file", "",
+		"File containing the passphrase of --keystore. Prompted for if not set")
+	flags.StringVar(&externalSignerURL, "external-signer", "",
+		"URL of a clef compatible external signer to sign the transactions with")
+	flags.StringVar(&signerAddress, "signer-address", "",
+		"Account of --external-signer to sign with. Defaults to its only account")
+}
+
+// newSigner returns the signer selected by the signer flags of cmd. The private key of
+// TELEPORTER_PRIVATE_KEY is used if none is set.
+func newSigner(cmd *cobra.Command) (txSigner, error) {
+	set := 0
+	for _, option := range []string{privateKeyHex, keystoreFile, externalSignerURL} {
+		if option != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, errMultipleSigners
+	}
+
+	switch {
+	case privateKeyHex != "":
+		return newKeySigner(privateKeyHex)
+	case keystoreFile != "":
+		return newKeystoreSigner(cmd, keystoreFile, passphraseFile)
+	case externalSignerURL != "":
+		return newRemoteSigner(externalSignerURL, signerAddress)
+	}
+	if keyStr := os.Getenv(privateKeyEnvVar); keyStr != "" {
+		return newKeySigner(keyStr)
+	}
+	return nil, errMissingSigner
+}
+
+func newKeySigner(keyStr string) (*keySigner, error) {
+	key, err := parsePrivateKey(keyStr)
+	if err != nil {
+		return nil, err
+	}
+	return &keySigner{key: key}, nil
+}
+
+// newKeystoreSigner decrypts a geth style keystore file, with the passphrase read from
+// passphrasePath or prompted for
+func newKeystoreSigner(cmd *cobra.Command, path string, passphrasePath string) (*keySigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %w", err)
+	}
+	var passphrase string
+	if passphrasePath != "" {
+		b, err := os.ReadFile(passphrasePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		passphrase = strings.TrimRight(string(b), "\r\n")
+	} else {
+		passphrase, err = promptPassphrase(cmd, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore file: %w", err)
+	}
+	return &keySigner{key: key.PrivateKey}, nil
+}
+
+// promptPassphrase reads a passphrase from stdin, without echoing it if stdin is a terminal
+func promptPassphrase(cmd *cobra.Command, path string) (string, error) {
+	cmd.PrintErrf("Passphrase for %s: ", path)
+	defer cmd.PrintErrln()
+	if f, ok := cmd.InOrStdin().(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		b, err := term.ReadPassword(int(f.Fd()))
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		return string(b), nil
+	}
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// newRemoteSigner connects to an external signer, and selects the account to sign with
+func newRemoteSigner(url string, from string) (*remoteSigner, error) {
+	signer, err := external.NewExternalSigner(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer: %w", err)
+	}
+	accts := signer.Accounts()
+	if from != "" {
+		if !common.IsHexAddress(from) {
+			return nil, fmt.Errorf("invalid --signer-address %s", from)
+		}
+		address := common.HexToAddress(from)
+		for _, account := range accts {
+			if account.Address == address {
+				return &remoteSigner{signer: signer, account: account}, nil
+			}
+		}
+		return nil, fmt.Errorf("external signer has no account %s", address.Hex())
+	}
+	switch len(accts) {
+	case 0:
+		return nil, errors.New("external signer has no accounts")
+	case 1:
+		return &remoteSigner{signer: signer, account: accts[0]}, nil
+	default:
+		return nil, errMissingSignerAccount
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/accounts/keystore"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ava-labs/subnet-evm/signer/core/apitypes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+const testPrivateKeyHex = "56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027"
+
+// writeTestKeystore encrypts key with a cheap scrypt work factor and writes it to a keystore file
+func writeTestKeystore(t *testing.T, key *ecdsa.PrivateKey, passphrase string) string {
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{
+		Address:    crypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	}, passphrase, 2, 1)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(path, keyJSON, 0o600))
+	return path
+}
+
+func TestNewSigner(t *testing.T) {
+	key, err := parsePrivateKey(testPrivateKeyHex)
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	keystorePath := writeTestKeystore(t, key, "passphrase")
+	passphrasePath := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(passphrasePath, []byte("passphrase\n"), 0o600))
+
+	var tests = []struct {
+		name           string
+		privateKey     string
+		keystore       string
+		passphraseFile string
+		externalSigner string
+		env            string
+		stdin          string
+		err            error
+	}{
+		{
+			name:       "private key",
+			privateKey: "0x" + testPrivateKeyHex,
+		},
+		{
+			name: "env var",
+			env:  testPrivateKeyHex,
+		},
+		{
+			name:       "flag takes precedence over env var",
+			privateKey: testPrivateKeyHex,
+			env:        "invalid",
+		},
+		{
+			name:           "keystore with passphrase file",
+			keystore:       keystorePath,
+			passphraseFile: passphrasePath,
+		},
+		{
+			name:     "keystore with prompt",
+			keystore: keystorePath,
+			stdin:    "passphrase\n",
+		},
+		{
+			name:     "keystore with wrong passphrase",
+			keystore: keystorePath,
+			stdin:    "wrong\n",
+			err:      keystore.ErrDecrypt,
+		},
+		{
+			name:       "invalid private key",
+			privateKey: "0x01",
+			err:        errInvalidPrivateKeyString,
+		},
+		{
+			name:           "multiple signers",
+			privateKey:     testPrivateKeyHex,
+			externalSigner: "http://127.0.0.1:8550",
+			err:            errMultipleSigners,
+		},
+		{
+			name: "missing signer",
+			err:  errMissingSigner,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKeyHex = tt.privateKey
+			keystoreFile = tt.keystore
+			passphraseFile = tt.passphraseFile
+			externalSignerURL = tt.externalSigner
+			t.Setenv(privateKeyEnvVar, tt.env)
+			cmd := &cobra.Command{}
+			cmd.SetIn(strings.NewReader(tt.stdin))
+			cmd.SetErr(new(strings.Builder))
+
+			signer, err := newSigner(cmd)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, address, signer.Address())
+		})
+	}
+}
+
+// standInSigner is a local stand-in for a clef external signer, serving the account_version,
+// account_list and account_signTransaction methods
+type standInSigner struct {
+	keys []*ecdsa.PrivateKey
+	// tamper makes the signer sign a different transaction than the requested one
+	tamper bool
+}
+
+type standInSignResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+func (s *standInSigner) Version() string {
+	return "6.0.0"
+}
+
+func (s *standInSigner) List() []common.Address {
+	addresses := []common.Address{}
+	for _, key := range s.keys {
+		addresses = append(addresses, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	return addresses
+}
+
+func (s *standInSigner) SignTransaction(args apitypes.SendTxArgs) (*standInSignResult, error) {
+	if s.tamper {
+		args.Value = hexutil.Big(*big.NewInt(1))
+	}
+	tx := args.ToTransaction()
+	for _, key := range s.keys {
+		if crypto.PubkeyToAddress(key.PublicKey) == args.From.Address() {
+			signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(tx.ChainId()), key)
+			if err != nil {
+				return nil, err
+			}
+			raw, err := signedTx.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			return &standInSignResult{Raw: raw, Tx: signedTx}, nil
+		}
+	}
+	return nil, keystore.ErrNoMatch
+}
+
+func newStandInSignerServer(t *testing.T, signer *standInSigner) string {
+	server := rpc.NewServer(0)
+	require.NoError(t, server.RegisterName("account", signer))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL
+}
+
+func TestRemoteSigner(t *testing.T) {
+	keyA, err := parsePrivateKey(testPrivateKeyHex)
+	require.NoError(t, err)
+	keyB, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	addressB := crypto.PubkeyToAddress(keyB.PublicKey)
+
+	chainID := big.NewInt(43112)
+	to := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     3,
+		To:        &to,
+		Gas:       100_000,
+		GasFeeCap: big.NewInt(25_000_000_000),
+		GasTipCap: big.NewInt(1),
+		Value:     common.Big0,
+		Data:      []byte{0x01, 0x02},
+	})
+
+	var tests = []struct {
+		name    string
+		keys    []*ecdsa.PrivateKey
+		from    string
+		tamper  bool
+		address common.Address
+		err     string
+	}{
+		{
+			name:    "only account",
+			keys:    []*ecdsa.PrivateKey{keyB},
+			address: addressB,
+		},
+		{
+			name:    "from account",
+			keys:    []*ecdsa.PrivateKey{keyA, keyB},
+			from:    addressB.Hex(),
+			address: addressB,
+		},
+		{
+			name: "several accounts",
+			keys: []*ecdsa.PrivateKey{keyA, keyB},
+			err:  errMissingSignerAccount.Error(),
+		},
+		{
+			name: "unknown from account",
+			keys: []*ecdsa.PrivateKey{keyA},
+			from: addressB.Hex(),
+			err:  "external signer has no account",
+		},
+		{
+			name:    "tampered transaction",
+			keys:    []*ecdsa.PrivateKey{keyB},
+			tamper:  true,
+			address: addressB,
+			err:     "external signer returned a different transaction",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := newStandInSignerServer(t, &standInSigner{keys: tt.keys, tamper: tt.tamper})
+			signer, err := newRemoteSigner(url, tt.from)
+			if err == nil {
+				require.Equal(t, tt.address, signer.Address())
+				var signedTx *types.Transaction
+				signedTx, err = signer.SignTx(tx, chainID)
+				if err == nil {
+					sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+					require.NoError(t, err)
+					require.Equal(t, tt.address, sender)
+				}
+			}
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/rpc"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// teleporterReceiverABI is the ABI of ITeleporterReceiver, which has no Go binding
+const teleporterReceiverABI = `[
+	{"type": "function", "name": "receiveTeleporterMessage", "stateMutability": "nonpayable",
+		"inputs": [
+			{"name": "originBlockchainID", "type": "bytes32"},
+			{"name": "originSenderAddress", "type": "address"},
+			{"name": "message", "type": "bytes"}
+		],
+		"outputs": []}
+]`
+
+var (
+	simulateFile   string
+	simulateTxHash string
+	simulateOrigin string
+
+	errMissingSimulateOrigin = errors.New("--origin is required unless the message is read from --tx")
+)
+
+// simulationClient is the subset of ethclient.Client used to simulate the execution of a message
+type simulationClient interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, call interfaces.CallMsg, blockNumber *big.Int) ([]byte, error)
+	EstimateGas(ctx context.Context, call interfaces.CallMsg) (uint64, error)
+}
+
+// simulationOutput is the result of a simulate command. GasUsed is the estimated gas used by
+// the execution, and is only set if the execution succeeds.
+type simulationOutput struct {
+	OriginBlockchainID      blockchainIDOutput `json:"originBlockchainID"`
+	DestinationBlockchainID blockchainIDOutput `json:"destinationBlockchainID"`
+	MessageID               string             `json:"messageID"`
+	DestinationAddress      string             `json:"destinationAddress"`
+	RequiredGasLimit        string             `json:"requiredGasLimit"`
+	Success                 bool               `json:"success"`
+	RevertReason            string             `json:"revertReason,omitempty"`
+	GasUsed                 uint64             `json:"gasUsed,omitempty"`
+}
+
+var simulateCmd = &cobra.Command{
+	Use: "simulate --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"(MESSAGE_BYTES --origin BLOCKCHAIN_ID | --file INPUT_FILE --origin BLOCKCHAIN_ID | " +
+		"--tx TRANSACTION_HASH --source-rpc RPC_URL)",
+	Short: "Simulates the execution of a Teleporter message on the destination chain",
+	Long: `Simulates the execution of a Teleporter message on the --rpc destination chain
+before it is delivered, with an eth_call of receiveTeleporterMessage on the
+message's destination address from the Teleporter contract address, limited
+to the message's requiredGasLimit, as the Teleporter contract executes it.
+Reports whether the execution succeeds, the revert reason if it fails, and
+the gas the execution uses. Messages to an address without contract code,
+and messages whose requiredGasLimit is too low to execute, are delivered
+but fail to execute, and have to be retried.
+
+The message is given as hex encoded bytes, as a JSON or YAML file using the
+same schema as the output of the message command with --file, or as the hash
+of the source chain transaction that sent it with --tx and --source-rpc.
+Messages given as bytes or files need the --origin blockchain ID, in cb58, hex
+or as a chain name, since it is not part of the message.
+
+The simulation assumes that the relayer provides the message's
+requiredGasLimit to the execution, and runs against the latest state of the
+destination chain, which may change before the message is delivered.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if simulateTxHash == "" && simulateOrigin == "" {
+			return errMissingSimulateOrigin
+		}
+		if simulateFile != "" || simulateTxHash != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: simulateRun,
+}
+
+func simulateRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	var message teleportermessenger.TeleporterMessage
+	var originBlockchainID ids.ID
+	var err error
+	switch {
+	case simulateTxHash != "":
+		message, originBlockchainID, err = getSentMessage(ctx, common.HexToHash(simulateTxHash))
+	case simulateFile != "":
+		var b []byte
+		b, err = readInputFile(cmd, simulateFile)
+		cobra.CheckErr(err)
+		message, err = parseMessageInput(b)
+	default:
+		var b []byte
+		b, err = hexutil.Decode(ensureHexPrefix(args[0]))
+		cobra.CheckErr(err)
+		var m *teleportermessenger.TeleporterMessage
+		m, err = teleportermessenger.UnpackTeleporterMessage(b)
+		if m != nil {
+			message = *m
+		}
+	}
+	cobra.CheckErr(err)
+	if simulateOrigin != "" {
+		origin, err := resolveBlockchainID(simulateOrigin)
+		cobra.CheckErr(err)
+		if simulateTxHash != "" && origin != originBlockchainID {
+			cobra.CheckErr(fmt.Errorf("message was sent from %s, not from --origin %s", originBlockchainID, origin))
+		}
+		originBlockchainID = origin
+	}
+
+	destinationBlockchainID, err := getBlockchainID(ctx, client)
+	cobra.CheckErr(err)
+	if ids.ID(message.DestinationBlockchainID) != destinationBlockchainID {
+		cobra.CheckErr(fmt.Errorf("message is sent to %s, not to the chain at %s",
+			ids.ID(message.DestinationBlockchainID), rpcEndpoint))
+	}
+	logger.Debug("Simulating message execution",
+		zap.String("messageID", message.MessageID.String()),
+		zap.String("destinationAddress", message.DestinationAddress.Hex()),
+		zap.String("requiredGasLimit", message.RequiredGasLimit.String()))
+
+	out, err := simulateMessage(ctx, client, originBlockchainID, message)
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, out)
+	cmd.Println("Simulate command ran successfully")
+}
+
+// getSentMessage returns the message sent to the --rpc chain by a source chain transaction,
+// and the blockchain ID of the source chain
+func getSentMessage(ctx context.Context, txHash common.Hash) (teleportermessenger.TeleporterMessage, ids.ID, error) {
+	c, err := ethclient.Dial(sourceRPCEndpoint)
+	if err != nil {
+		return teleportermessenger.TeleporterMessage{}, ids.Empty, err
+	}
+	sourceBlockchainID, err := getBlockchainID(ctx, c)
+	if err != nil {
+		return teleportermessenger.TeleporterMessage{}, ids.Empty, err
+	}
+	destinationBlockchainID, err := getBlockchainID(ctx, client)
+	if err != nil {
+		return teleportermessenger.TeleporterMessage{}, ids.Empty, err
+	}
+	messenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, c)
+	if err != nil {
+		return teleportermessenger.TeleporterMessage{}, ids.Empty, err
+	}
+	receipt, err := c.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return teleportermessenger.TeleporterMessage{}, ids.Empty, err
+	}
+	event, err := getSendEventToDestination(receipt.Logs, messenger, destinationBlockchainID)
+	if err != nil {
+		return teleportermessenger.TeleporterMessage{}, ids.Empty, err
+	}
+	return event.Message, sourceBlockchainID, nil
+}
+
+// simulateMessage simulates the execution of message by the Teleporter contract on the destination
+// chain. Only errors of the RPC calls are returned, failed executions are reported in the output.
+func simulateMessage(
+	ctx context.Context,
+	client simulationClient,
+	originBlockchainID ids.ID,
+	message teleportermessenger.TeleporterMessage,
+) (simulationOutput, error) {
+	out := simulationOutput{
+		OriginBlockchainID:      newBlockchainIDOutput(originBlockchainID),
+		DestinationBlockchainID: newBlockchainIDOutput(message.DestinationBlockchainID),
+		MessageID:               bigIntString(message.MessageID),
+		DestinationAddress:      message.DestinationAddress.Hex(),
+		RequiredGasLimit:        bigIntString(message.RequiredGasLimit),
+	}
+
+	// The Teleporter contract stores messages to addresses without code as failed executions,
+	// without calling them.
+	code, err := client.CodeAt(ctx, message.DestinationAddress, nil)
+	if err != nil {
+		return out, err
+	}
+	if len(code) == 0 {
+		out.RevertReason = fmt.Sprintf("destination address %s has no contract code", message.DestinationAddress)
+		return out, nil
+	}
+
+	receiverABI, err := abi.JSON(strings.NewReader(teleporterReceiverABI))
+	if err != nil {
+		return out, err
+	}
+	data, err := receiverABI.Pack("receiveTeleporterMessage",
+		originBlockchainID, message.SenderAddress, message.Message)
+	if err != nil {
+		return out, err
+	}
+	// The Teleporter contract limits the execution to requiredGasLimit, which does not include the
+	// intrinsic gas of the simulated transaction.
+	intrinsicGas, err := core.IntrinsicGas(data, nil, false, params.Rules{IsHomestead: true, IsIstanbul: true})
+	if err != nil {
+		return out, err
+	}
+	if !message.RequiredGasLimit.IsUint64() {
+		return out, fmt.Errorf("required gas limit %s is out of range", message.RequiredGasLimit)
+	}
+	call := interfaces.CallMsg{
+		From: teleporterAddress,
+		To:   &message.DestinationAddress,
+		Data: data,
+	}
+
+	// The gas used is estimated without the gas limit, so that it is also reported for messages
+	// whose requiredGasLimit is too low.
+	estimatedGas, err := client.EstimateGas(ctx, call)
+	if err != nil {
+		if !isExecutionError(err) {
+			return out, err
+		}
+		out.RevertReason = revertReason(err)
+		return out, nil
+	}
+	gasUsed := estimatedGas - intrinsicGas
+
+	call.Gas = intrinsicGas + message.RequiredGasLimit.Uint64()
+	if _, err := client.CallContract(ctx, call, nil); err != nil {
+		if !isExecutionError(err) {
+			return out, err
+		}
+		out.RevertReason = revertReason(err)
+		if gasUsed > message.RequiredGasLimit.Uint64() {
+			out.RevertReason = fmt.Sprintf("%s: execution needs %d gas, more than the required gas limit %s",
+				out.RevertReason, gasUsed, message.RequiredGasLimit)
+		}
+		return out, nil
+	}
+	out.Success = true
+	out.GasUsed = gasUsed
+	return out, nil
+}
+
+// isExecutionError returns whether err was returned by the node for a failed call, rather than
+// being an error of the connection to the node
+func isExecutionError(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr)
+}
+
+// revertReason returns the Error(string) revert reason of a failed call, or the error message
+// if the call did not revert with a reason
+func revertReason(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if b, err := hexutil.Decode(data); err == nil {
+				if reason, err := abi.UnpackRevert(b); err == nil {
+					return reason
+				}
+			}
+		}
+	}
+	return err.Error()
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+	simulateCmd.PersistentFlags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint of the destination chain")
+	address := simulateCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addChainFlag(simulateCmd)
+	simulateCmd.Flags().StringVar(&simulateFile, "file", "",
+		"JSON or YAML file containing the message, or - to read it from stdin")
+	simulateCmd.Flags().StringVar(&simulateTxHash, "tx", "",
+		"Hash of the source chain transaction that sent the message")
+	simulateCmd.Flags().StringVar(&sourceRPCEndpoint, "source-rpc", "", "RPC endpoint of the source chain")
+	simulateCmd.Flags().StringVar(&simulateOrigin, "origin", "",
+		"Blockchain ID of the chain the message is sent from")
+	err := simulateCmd.MarkPersistentFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = simulateCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	simulateCmd.MarkFlagsMutuallyExclusive("file", "tx")
+	simulateCmd.MarkFlagsRequiredTogether("tx", "source-rpc")
+	simulateCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return rpcPreRunE(cmd, args, address)
+	}
+}
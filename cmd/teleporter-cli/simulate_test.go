@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/params"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no origin",
+			args: []string{"simulate", "0x00"},
+			err:  errMissingSimulateOrigin,
+		},
+		{
+			name: "no message",
+			args: []string{"simulate", "--origin", "C"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "file and args",
+			args: []string{"simulate", "--origin", "C", "--file", "message.json", "0x00"},
+			err:  fmt.Errorf("unknown command \"0x00\" for \"teleporter-cli simulate\""),
+		},
+		{
+			name: "help",
+			args: []string{"simulate", "--help"},
+			err:  nil,
+			out:  "Simulates the execution of a Teleporter message on the --rpc destination chain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simulateFile = ""
+			simulateOrigin = ""
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+// revertError is an execution error returned by a node, with the revert data of the call
+type revertError struct {
+	message string
+	data    []byte
+}
+
+func (e *revertError) Error() string          { return e.message }
+func (e *revertError) ErrorCode() int         { return 3 }
+func (e *revertError) ErrorData() interface{} { return hexutil.Encode(e.data) }
+
+func newRevertError(t *testing.T, reason string) *revertError {
+	data, err := newArguments(abi.ArgumentMarshaling{Type: "string"}).Pack(reason)
+	require.NoError(t, err)
+	return &revertError{
+		message: "execution reverted: " + reason,
+		data:    append(crypto.Keccak256([]byte("Error(string)"))[:4], data...),
+	}
+}
+
+// staticReceiver simulates a receiver contract that uses a fixed amount of gas, or reverts
+type staticReceiver struct {
+	t            *testing.T
+	code         []byte
+	executionGas uint64
+	revert       error
+}
+
+func (r *staticReceiver) CodeAt(_ context.Context, _ common.Address, _ *big.Int) ([]byte, error) {
+	return r.code, nil
+}
+
+func (r *staticReceiver) intrinsicGas(call interfaces.CallMsg) uint64 {
+	require.Equal(r.t, teleporterAddress, call.From)
+	gas, err := core.IntrinsicGas(call.Data, nil, false, params.Rules{IsHomestead: true, IsIstanbul: true})
+	require.NoError(r.t, err)
+	return gas
+}
+
+func (r *staticReceiver) CallContract(_ context.Context, call interfaces.CallMsg, _ *big.Int) ([]byte, error) {
+	if r.revert != nil {
+		return nil, r.revert
+	}
+	if call.Gas < r.intrinsicGas(call)+r.executionGas {
+		return nil, &revertError{message: "out of gas"}
+	}
+	return nil, nil
+}
+
+func (r *staticReceiver) EstimateGas(_ context.Context, call interfaces.CallMsg) (uint64, error) {
+	if r.revert != nil {
+		return 0, r.revert
+	}
+	return r.intrinsicGas(call) + r.executionGas, nil
+}
+
+func TestSimulateMessage(t *testing.T) {
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		SenderAddress:           common.HexToAddress("0x01"),
+		DestinationBlockchainID: ids.ID{2},
+		DestinationAddress:      common.HexToAddress("0x02"),
+		RequiredGasLimit:        big.NewInt(100000),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte("hello"),
+	}
+
+	var tests = []struct {
+		name         string
+		receiver     *staticReceiver
+		success      bool
+		revertReason string
+		gasUsed      uint64
+		err          string
+	}{
+		{
+			name:     "success",
+			receiver: &staticReceiver{code: []byte{1}, executionGas: 50000},
+			success:  true,
+			gasUsed:  50000,
+		},
+		{
+			name:         "no contract",
+			receiver:     &staticReceiver{},
+			revertReason: "destination address 0x0000000000000000000000000000000000000002 has no contract code",
+		},
+		{
+			name:         "insufficient gas",
+			receiver:     &staticReceiver{code: []byte{1}, executionGas: 150000},
+			revertReason: "out of gas: execution needs 150000 gas, more than the required gas limit 100000",
+		},
+		{
+			name:         "revert",
+			receiver:     &staticReceiver{code: []byte{1}, revert: newRevertError(t, "ExampleApp: invalid message")},
+			revertReason: "ExampleApp: invalid message",
+		},
+		{
+			name:     "connection error",
+			receiver: &staticReceiver{code: []byte{1}, revert: errors.New("connection refused")},
+			err:      "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.receiver.t = t
+			out, err := simulateMessage(context.Background(), tt.receiver, ids.ID{1}, message)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.success, out.Success)
+			require.Equal(t, tt.revertReason, out.RevertReason)
+			require.Equal(t, tt.gasUsed, out.GasUsed)
+			require.Equal(t, "100000", out.RequiredGasLimit)
+		})
+	}
+}
@@ -0,0 +1,304 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultLookbackBlocks = 500
+
+	executionUnknown   = "unknown"
+	executionPending   = "pending"
+	executionSucceeded = "succeeded"
+	executionFailed    = "failed"
+)
+
+var (
+	sourceRPCEndpoint string
+	destRPCEndpoint   string
+	sourceClient      ethclient.Client
+	destClient        ethclient.Client
+	statusTxHash      string
+	lookbackBlocks    uint64
+)
+
+// sourceMessageReader reads the state of a sent message stored by the source chain TeleporterMessenger
+type sourceMessageReader interface {
+	GetMessageHash(opts *bind.CallOpts, destinationBlockchainID [32]byte, messageID *big.Int) ([32]byte, error)
+	GetFeeInfo(opts *bind.CallOpts, destinationBlockchainID [32]byte, messageID *big.Int) (common.Address, *big.Int, error)
+}
+
+// destinationMessageReader reads the state of a received message stored by the destination chain
+// TeleporterMessenger
+type destinationMessageReader interface {
+	MessageReceived(opts *bind.CallOpts, originBlockchainID [32]byte, messageID *big.Int) (bool, error)
+	GetRelayerRewardAddress(opts *bind.CallOpts, originBlockchainID [32]byte, messageID *big.Int) (common.Address, error)
+}
+
+// messageStatus is the lifecycle of a single Teleporter message, from the source chain
+// to the destination chain and back.
+type messageStatus struct {
+	MessageID               string             `json:"messageID"`
+	SourceBlockchainID      blockchainIDOutput `json:"sourceBlockchainID"`
+	DestinationBlockchainID blockchainIDOutput `json:"destinationBlockchainID"`
+	Sent                    bool               `json:"sent"`
+	SendTxHash              string             `json:"sendTxHash,omitempty"`
+	FeeInfo                 feeInfoOutput      `json:"feeInfo"`
+	Delivered               bool               `json:"delivered"`
+	DeliveryTxHash          string             `json:"deliveryTxHash,omitempty"`
+	RelayerRewardAddress    string             `json:"relayerRewardAddress,omitempty"`
+	Execution               string             `json:"execution"`
+	ReceiptReceived         bool               `json:"receiptReceived"`
+}
+
+var statusCmd = &cobra.Command{
+	Use: "status --source-rpc RPC_URL --dest-rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"[MESSAGE_ID | --tx TRANSACTION_HASH]",
+	Short: "Reports the delivery lifecycle of a Teleporter message",
+	Long: `Given a Teleporter message ID, or the hash of the source transaction that sent it,
+this command reports the full lifecycle of the message: whether it was sent,
+its fee info, whether it was delivered and by which relayer, whether its
+execution succeeded or failed, and whether the delivery receipt has been
+sent back to the source chain. Execution results are looked up in the most
+recent --lookback-blocks blocks of the destination chain.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if statusTxHash != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: statusRun,
+}
+
+func statusRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	sourceBlockchainID, err := getBlockchainID(ctx, sourceClient)
+	cobra.CheckErr(err)
+	destinationBlockchainID, err := getBlockchainID(ctx, destClient)
+	cobra.CheckErr(err)
+
+	sourceMessenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, sourceClient)
+	cobra.CheckErr(err)
+	destMessenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, destClient)
+	cobra.CheckErr(err)
+
+	status := messageStatus{
+		SourceBlockchainID:      newBlockchainIDOutput(sourceBlockchainID),
+		DestinationBlockchainID: newBlockchainIDOutput(destinationBlockchainID),
+		Execution:               executionPending,
+	}
+
+	var messageID *big.Int
+	if statusTxHash != "" {
+		txHash := common.HexToHash(statusTxHash)
+		receipt, err := sourceClient.TransactionReceipt(ctx, txHash)
+		cobra.CheckErr(err)
+
+		sendEvent, err := getSendEventToDestination(receipt.Logs, sourceMessenger, destinationBlockchainID)
+		cobra.CheckErr(err)
+		messageID = sendEvent.MessageID
+		status.Sent = true
+		status.SendTxHash = txHash.Hex()
+	} else {
+		var ok bool
+		messageID, ok = new(big.Int).SetString(args[0], 0)
+		if !ok {
+			cobra.CheckErr(fmt.Errorf("invalid message ID %s", args[0]))
+		}
+
+		startBlock, err := getLookbackStartBlock(ctx, sourceClient, lookbackBlocks)
+		cobra.CheckErr(err)
+		it, err := sourceMessenger.FilterSendCrossChainMessage(
+			&bind.FilterOpts{Start: startBlock, Context: ctx},
+			[][32]byte{destinationBlockchainID},
+			[]*big.Int{messageID},
+		)
+		cobra.CheckErr(err)
+		for it.Next() {
+			status.Sent = true
+			status.SendTxHash = it.Event.Raw.TxHash.Hex()
+		}
+		cobra.CheckErr(it.Error())
+	}
+	logger.Debug("Checking message status",
+		zap.String("messageID", messageID.String()),
+		zap.String("sourceBlockchainID", sourceBlockchainID.String()),
+		zap.String("destinationBlockchainID", destinationBlockchainID.String()))
+	err = completeMessageStatus(
+		ctx, &status, sourceMessenger, destMessenger, destClient,
+		sourceBlockchainID, destinationBlockchainID, messageID, lookbackBlocks,
+	)
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, status)
+	cmd.Println("Status command ran successfully")
+}
+
+// completeMessageStatus fills in the fee info, delivery, execution result and receipt of the message
+// in status from the TeleporterMessenger state of both chains. Execution results are looked up in
+// the most recent lookback blocks of destLogs.
+func completeMessageStatus(
+	ctx context.Context,
+	status *messageStatus,
+	source sourceMessageReader,
+	destination destinationMessageReader,
+	destLogs latestLogReader,
+	sourceBlockchainID ids.ID,
+	destinationBlockchainID ids.ID,
+	messageID *big.Int,
+	lookback uint64,
+) error {
+	opts := &bind.CallOpts{Context: ctx}
+	status.MessageID = messageID.String()
+
+	// The message hash is only stored on the source chain until the receipt for the message is received.
+	messageHash, err := source.GetMessageHash(opts, destinationBlockchainID, messageID)
+	if err != nil {
+		return err
+	}
+	feeTokenAddress, feeAmount, err := source.GetFeeInfo(opts, destinationBlockchainID, messageID)
+	if err != nil {
+		return err
+	}
+	status.FeeInfo = newFeeInfoOutput(teleportermessenger.TeleporterFeeInfo{
+		FeeTokenAddress: feeTokenAddress,
+		Amount:          feeAmount,
+	})
+
+	status.Delivered, err = destination.MessageReceived(opts, sourceBlockchainID, messageID)
+	if err != nil {
+		return err
+	}
+	status.Sent = status.Sent || status.Delivered || messageHash != [32]byte{}
+	status.ReceiptReceived = status.Sent && messageHash == [32]byte{}
+	if !status.Delivered {
+		return nil
+	}
+
+	relayerRewardAddress, err := destination.GetRelayerRewardAddress(opts, sourceBlockchainID, messageID)
+	if err != nil {
+		return err
+	}
+	status.RelayerRewardAddress = relayerRewardAddress.Hex()
+	deliveryTxHash, execution, err := getExecutionResult(ctx, destLogs, sourceBlockchainID, messageID, lookback)
+	if err != nil {
+		return err
+	}
+	status.DeliveryTxHash = hashString(deliveryTxHash)
+	status.Execution = execution
+	return nil
+}
+
+// getExecutionResult looks up the delivery and execution logs of a delivered message in the most
+// recent lookback blocks of the destination chain. Executed takes precedence over failed, since a
+// failed execution may have been successfully retried since.
+func getExecutionResult(
+	ctx context.Context,
+	client latestLogReader,
+	sourceBlockchainID ids.ID,
+	messageID *big.Int,
+	lookback uint64,
+) (*common.Hash, string, error) {
+	receiveID := teleporterABI.Events[teleportermessenger.ReceiveCrossChainMessage.String()].ID
+	executedID := teleporterABI.Events[teleportermessenger.MessageExecuted.String()].ID
+	failedID := teleporterABI.Events[teleportermessenger.MessageExecutionFailed.String()].ID
+	query := interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress},
+		Topics: [][]common.Hash{
+			{receiveID, executedID, failedID},
+			{common.Hash(sourceBlockchainID)},
+			{common.BigToHash(messageID)},
+		},
+	}
+
+	var (
+		deliveryTxHash   *common.Hash
+		executed, failed bool
+	)
+	err := filterRecentLogs(ctx, client, query, lookback, func(log types.Log) error {
+		if len(log.Topics) == 0 {
+			return nil
+		}
+		switch log.Topics[0] {
+		case receiveID:
+			txHash := log.TxHash
+			deliveryTxHash = &txHash
+		case executedID:
+			executed = true
+		case failedID:
+			failed = true
+		}
+		return nil
+	})
+	switch {
+	case err != nil:
+		return deliveryTxHash, executionUnknown, err
+	case executed:
+		return deliveryTxHash, executionSucceeded, nil
+	case failed:
+		return deliveryTxHash, executionFailed, nil
+	default:
+		// The message was delivered before the lookback window.
+		return deliveryTxHash, executionUnknown, nil
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.PersistentFlags().StringVar(&sourceRPCEndpoint, "source-rpc", "", "RPC endpoint of the source chain")
+	statusCmd.PersistentFlags().StringVar(&destRPCEndpoint, "dest-rpc", "", "RPC endpoint of the destination chain")
+	address := statusCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addFromToFlags(statusCmd)
+	statusCmd.Flags().StringVar(&statusTxHash, "tx", "",
+		"Hash of the source chain transaction that sent the message")
+	statusCmd.Flags().Uint64Var(&lookbackBlocks, "lookback-blocks", defaultLookbackBlocks,
+		"Number of recent blocks to search for message logs")
+	err := statusCmd.MarkPersistentFlagRequired("source-rpc")
+	cobra.CheckErr(err)
+	err = statusCmd.MarkPersistentFlagRequired("dest-rpc")
+	cobra.CheckErr(err)
+	err = statusCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	statusCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return sourceDestPreRunE(cmd, args, address)
+	}
+}
+
+func sourceDestPreRunE(cmd *cobra.Command, args []string, address *string) error {
+	// Run the persistent pre-run function of the root command if it exists.
+	if err := callPersistentPreRunE(cmd, args); err != nil {
+		return err
+	}
+	if err := applyFromToProfiles(cmd); err != nil {
+		return err
+	}
+	teleporterAddress = common.HexToAddress(*address)
+	c, err := ethclient.Dial(sourceRPCEndpoint)
+	if err != nil {
+		return err
+	}
+	sourceClient = c
+
+	c, err = ethclient.Dial(destRPCEndpoint)
+	if err != nil {
+		return err
+	}
+	destClient = c
+	return nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"status"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"status", "--help"},
+			err:  nil,
+			out:  "Given a Teleporter message ID, or the hash of the source transaction",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+// staticMessenger is the TeleporterMessenger state of a single message on its source and destination chains
+type staticMessenger struct {
+	sourceBlockchainID      ids.ID
+	destinationBlockchainID ids.ID
+	messageID               *big.Int
+	messageHash             [32]byte
+	feeInfo                 teleportermessenger.TeleporterFeeInfo
+	received                bool
+	rewardAddress           common.Address
+}
+
+var errUnexpectedMessage = errors.New("unexpected message")
+
+func (m *staticMessenger) checkMessage(blockchainID [32]byte, expected ids.ID, messageID *big.Int) error {
+	if ids.ID(blockchainID) != expected || messageID.Cmp(m.messageID) != 0 {
+		return errUnexpectedMessage
+	}
+	return nil
+}
+
+func (m *staticMessenger) GetMessageHash(
+	_ *bind.CallOpts, destinationBlockchainID [32]byte, messageID *big.Int,
+) ([32]byte, error) {
+	return m.messageHash, m.checkMessage(destinationBlockchainID, m.destinationBlockchainID, messageID)
+}
+
+func (m *staticMessenger) GetFeeInfo(
+	_ *bind.CallOpts, destinationBlockchainID [32]byte, messageID *big.Int,
+) (common.Address, *big.Int, error) {
+	err := m.checkMessage(destinationBlockchainID, m.destinationBlockchainID, messageID)
+	return m.feeInfo.FeeTokenAddress, m.feeInfo.Amount, err
+}
+
+func (m *staticMessenger) MessageReceived(
+	_ *bind.CallOpts, originBlockchainID [32]byte, messageID *big.Int,
+) (bool, error) {
+	return m.received, m.checkMessage(originBlockchainID, m.sourceBlockchainID, messageID)
+}
+
+func (m *staticMessenger) GetRelayerRewardAddress(
+	_ *bind.CallOpts, originBlockchainID [32]byte, messageID *big.Int,
+) (common.Address, error) {
+	return m.rewardAddress, m.checkMessage(originBlockchainID, m.sourceBlockchainID, messageID)
+}
+
+func TestCompleteMessageStatus(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	chainA := ids.ID{1}
+	chainB := ids.ID{2}
+	messageID := big.NewInt(7)
+	rewardAddress := common.HexToAddress("0xa0")
+	feeInfo := teleportermessenger.TeleporterFeeInfo{FeeTokenAddress: common.HexToAddress("0x01"), Amount: big.NewInt(10)}
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               messageID,
+		DestinationBlockchainID: chainB,
+		RequiredGasLimit:        big.NewInt(0),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{},
+	}
+	indexed := []common.Hash{common.Hash(chainA), common.BigToHash(messageID)}
+	at := func(log types.Log, block uint64, tx byte) types.Log {
+		log.BlockNumber = block
+		log.TxHash = common.Hash{tx}
+		return log
+	}
+	received := at(packTeleporterLog(t, "ReceiveCrossChainMessage",
+		append(indexed, common.BytesToHash(common.HexToAddress("0xd0").Bytes())), rewardAddress, message), 5, 1)
+	failed := at(packTeleporterLog(t, "MessageExecutionFailed", indexed, message), 5, 1)
+	executed := at(packTeleporterLog(t, "MessageExecuted", indexed), 8, 2)
+
+	var tests = []struct {
+		name     string
+		sent     bool
+		hash     [32]byte
+		received bool
+		logs     []types.Log
+		lookback uint64
+		expected messageStatus
+	}{
+		{
+			name: "not sent",
+			expected: messageStatus{
+				Execution: executionPending,
+			},
+		},
+		{
+			name: "pending delivery",
+			hash: [32]byte{1},
+			expected: messageStatus{
+				Sent:      true,
+				Execution: executionPending,
+			},
+		},
+		{
+			name:     "failed",
+			hash:     [32]byte{1},
+			received: true,
+			logs:     []types.Log{received, failed},
+			lookback: 10,
+			expected: messageStatus{
+				Sent:                 true,
+				Delivered:            true,
+				DeliveryTxHash:       common.Hash{1}.Hex(),
+				RelayerRewardAddress: rewardAddress.Hex(),
+				Execution:            executionFailed,
+			},
+		},
+		{
+			name:     "retried after failure",
+			received: true,
+			logs:     []types.Log{received, failed, executed},
+			lookback: 10,
+			expected: messageStatus{
+				Sent:                 true,
+				Delivered:            true,
+				DeliveryTxHash:       common.Hash{1}.Hex(),
+				RelayerRewardAddress: rewardAddress.Hex(),
+				Execution:            executionSucceeded,
+				ReceiptReceived:      true,
+			},
+		},
+		{
+			name:     "delivered before lookback",
+			sent:     true,
+			received: true,
+			logs:     []types.Log{received, failed, executed},
+			lookback: 1,
+			expected: messageStatus{
+				Sent:                 true,
+				Delivered:            true,
+				RelayerRewardAddress: rewardAddress.Hex(),
+				Execution:            executionUnknown,
+				ReceiptReceived:      true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messenger := &staticMessenger{
+				sourceBlockchainID:      chainA,
+				destinationBlockchainID: chainB,
+				messageID:               messageID,
+				messageHash:             tt.hash,
+				feeInfo:                 feeInfo,
+				received:                tt.received,
+				rewardAddress:           rewardAddress,
+			}
+			status := messageStatus{Sent: tt.sent, Execution: executionPending}
+			err := completeMessageStatus(context.Background(), &status, messenger, messenger,
+				newStaticChain(0, 1, 11, tt.logs...), chainA, chainB, messageID, tt.lookback)
+			require.NoError(t, err)
+
+			expected := tt.expected
+			expected.MessageID = messageID.String()
+			expected.FeeInfo = newFeeInfoOutput(feeInfo)
+			require.Equal(t, expected, status)
+		})
+	}
+
+	// The message is looked up by its route
+	status := messageStatus{}
+	err = completeMessageStatus(context.Background(), &status, &staticMessenger{messageID: messageID},
+		&staticMessenger{messageID: messageID}, newStaticChain(0, 1, 1), chainA, chainB, messageID, 10)
+	require.ErrorIs(t, err, errUnexpectedMessage)
+}
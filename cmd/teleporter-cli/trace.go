@@ -0,0 +1,346 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	traceStepSent            = "sent"
+	traceStepDelivered       = "delivered"
+	traceStepExecuted        = "executed"
+	traceStepReceiptReceived = "receiptReceived"
+	traceStepRewardRedeemed  = "rewardRedeemed"
+)
+
+var traceChunkSize uint64
+
+// traceChain is the subset of ethclient.Client used to trace a message on one chain
+type traceChain interface {
+	logFilterer
+	headerReader
+}
+
+// traceStepOutput is a step of the lifecycle of a message, at the block of the log that records it
+type traceStepOutput struct {
+	Step          string             `json:"step"`
+	BlockchainID  blockchainIDOutput `json:"blockchainID"`
+	BlockNumber   uint64             `json:"blockNumber"`
+	BlockTime     string             `json:"blockTime"`
+	TxHash        string             `json:"txHash"`
+	SincePrevious string             `json:"sincePrevious,omitempty"`
+	SinceSent     string             `json:"sinceSent,omitempty"`
+
+	// timestamp is the block timestamp in seconds, to order the steps and compute their durations
+	timestamp uint64
+}
+
+// messageTraceOutput is the timeline of a message, in block time order
+type messageTraceOutput struct {
+	MessageID               string             `json:"messageID"`
+	SourceBlockchainID      blockchainIDOutput `json:"sourceBlockchainID"`
+	DestinationBlockchainID blockchainIDOutput `json:"destinationBlockchainID"`
+	FeeInfo                 feeInfoOutput      `json:"feeInfo"`
+	Deliverer               string             `json:"deliverer,omitempty"`
+	RelayerRewardAddress    string             `json:"relayerRewardAddress,omitempty"`
+	Execution               string             `json:"execution"`
+	Steps                   []traceStepOutput  `json:"steps"`
+}
+
+var traceCmd = &cobra.Command{
+	Use:   "trace --source-rpc RPC_URL --dest-rpc RPC_URL --teleporter-address CONTRACT_ADDRESS TRANSACTION_HASH",
+	Short: "Reports the cross chain latency timeline of a Teleporter message",
+	Long: `Given the hash of the source transaction that sent a Teleporter message, this
+command reports the block and block time of each step of the message's
+lifecycle, and the duration since the previous step and since the message
+was sent:
+
+  sent             the SendCrossChainMessage log on the source chain
+  delivered        the ReceiveCrossChainMessage log on the destination chain,
+                   with the result of the execution in the same transaction
+  executed         the MessageExecuted log of a successful retry, if the
+                   execution initially failed
+  receiptReceived  the ReceiveCrossChainMessage log on the source chain of
+                   the message that carried the receipt back
+  rewardRedeemed   the first RelayerRewardsRedeemed log of the relayer reward
+                   address for the fee token after the receipt was received
+
+Each chain is searched from the block time of the previous step to the latest
+block, in chunks of --chunk-size blocks. Steps that did not happen yet are
+omitted.`,
+	Args: cobra.ExactArgs(1),
+	Run:  traceRun,
+}
+
+func traceRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	sourceBlockchainID, err := getBlockchainID(ctx, sourceClient)
+	cobra.CheckErr(err)
+	destinationBlockchainID, err := getBlockchainID(ctx, destClient)
+	cobra.CheckErr(err)
+	sourceMessenger, err := teleportermessenger.NewTeleporterMessenger(teleporterAddress, sourceClient)
+	cobra.CheckErr(err)
+
+	receipt, err := sourceClient.TransactionReceipt(ctx, common.HexToHash(args[0]))
+	cobra.CheckErr(err)
+	sendEvent, err := getSendEventToDestination(receipt.Logs, sourceMessenger, destinationBlockchainID)
+	cobra.CheckErr(err)
+	logger.Debug("Tracing message",
+		zap.String("messageID", sendEvent.MessageID.String()),
+		zap.String("sourceBlockchainID", sourceBlockchainID.String()),
+		zap.String("destinationBlockchainID", destinationBlockchainID.String()))
+
+	trace, err := traceMessage(ctx, sourceClient, destClient, sourceBlockchainID, sendEvent, traceChunkSize)
+	cobra.CheckErr(err)
+
+	writeOutput(cmd, trace)
+	cmd.Println("Trace command ran successfully")
+}
+
+// traceMessage builds the timeline of the message of sendEvent, emitted on the source chain, by
+// searching each chain for the log of each step from the block time of the previous step
+func traceMessage(
+	ctx context.Context,
+	source traceChain,
+	destination traceChain,
+	sourceBlockchainID ids.ID,
+	sendEvent *teleportermessenger.TeleporterMessengerSendCrossChainMessage,
+	chunkSize uint64,
+) (trace messageTraceOutput, err error) {
+	destinationBlockchainID := ids.ID(sendEvent.DestinationBlockchainID)
+	messageID := sendEvent.MessageID
+	trace = messageTraceOutput{
+		MessageID:               messageID.String(),
+		SourceBlockchainID:      newBlockchainIDOutput(sourceBlockchainID),
+		DestinationBlockchainID: newBlockchainIDOutput(destinationBlockchainID),
+		FeeInfo:                 newFeeInfoOutput(sendEvent.FeeInfo),
+		Execution:               executionPending,
+		Steps:                   []traceStepOutput{},
+	}
+	addStep := func(step string, chain headerReader, blockchainID ids.ID, log types.Log) (uint64, error) {
+		header, err := chain.HeaderByNumber(ctx, new(big.Int).SetUint64(log.BlockNumber))
+		if err != nil {
+			return 0, err
+		}
+		trace.Steps = append(trace.Steps, traceStepOutput{
+			Step:         step,
+			BlockchainID: newBlockchainIDOutput(blockchainID),
+			BlockNumber:  log.BlockNumber,
+			BlockTime:    time.Unix(int64(header.Time), 0).UTC().Format(time.RFC3339),
+			TxHash:       log.TxHash.Hex(),
+			timestamp:    header.Time,
+		})
+		return header.Time, nil
+	}
+	defer func() { setTraceDurations(trace.Steps) }()
+
+	sentTime, err := addStep(traceStepSent, source, sourceBlockchainID, sendEvent.Raw)
+	if err != nil {
+		return trace, err
+	}
+
+	// The delivery and execution logs of the message on the destination chain
+	var delivery, executed, failed *types.Log
+	isMessage := func(originBlockchainID [32]byte, id *big.Int) bool {
+		return ids.ID(originBlockchainID) == sourceBlockchainID && id.Cmp(messageID) == 0
+	}
+	err = filterLogsSince(ctx, destination, sentTime, chunkSize, interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress},
+		Topics: [][]common.Hash{
+			{
+				teleporterEventID(teleportermessenger.ReceiveCrossChainMessage),
+				teleporterEventID(teleportermessenger.MessageExecuted),
+				teleporterEventID(teleportermessenger.MessageExecutionFailed),
+			},
+			{common.Hash(sourceBlockchainID)},
+			{common.BigToHash(messageID)},
+		},
+	}, func(log types.Log) error {
+		_, event, err := parseTeleporterLog(log.Topics, log.Data)
+		if err != nil {
+			return nil
+		}
+		switch e := event.(type) {
+		case *teleportermessenger.TeleporterMessengerReceiveCrossChainMessage:
+			if delivery == nil && isMessage(e.OriginBlockchainID, e.MessageID) {
+				delivery = &log
+				trace.Deliverer = e.Deliverer.Hex()
+			}
+		case *teleportermessenger.TeleporterMessengerMessageExecuted:
+			if executed == nil && isMessage(e.OriginBlockchainID, e.MessageID) {
+				executed = &log
+			}
+		case *teleportermessenger.TeleporterMessengerMessageExecutionFailed:
+			if failed == nil && isMessage(e.OriginBlockchainID, e.MessageID) {
+				failed = &log
+			}
+		}
+		return nil
+	})
+	if err != nil || delivery == nil {
+		return trace, err
+	}
+	deliveredTime, err := addStep(traceStepDelivered, destination, destinationBlockchainID, *delivery)
+	if err != nil {
+		return trace, err
+	}
+	switch {
+	case executed != nil && executed.TxHash == delivery.TxHash:
+		trace.Execution = executionSucceeded
+	case executed != nil:
+		// The execution failed on delivery, and was retried successfully since.
+		trace.Execution = executionSucceeded
+		if _, err := addStep(traceStepExecuted, destination, destinationBlockchainID, *executed); err != nil {
+			return trace, err
+		}
+	case failed != nil:
+		trace.Execution = executionFailed
+	default:
+		trace.Execution = executionUnknown
+	}
+
+	// The receipt is sent back to the source chain in a later message from the destination chain.
+	var receiptLog *types.Log
+	var rewardAddress common.Address
+	err = filterLogsSince(ctx, source, deliveredTime, chunkSize, interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress},
+		Topics: [][]common.Hash{
+			{teleporterEventID(teleportermessenger.ReceiveCrossChainMessage)},
+			{common.Hash(destinationBlockchainID)},
+		},
+	}, func(log types.Log) error {
+		if receiptLog != nil {
+			return nil
+		}
+		_, event, err := parseTeleporterLog(log.Topics, log.Data)
+		if err != nil {
+			return nil
+		}
+		e, ok := event.(*teleportermessenger.TeleporterMessengerReceiveCrossChainMessage)
+		if !ok || ids.ID(e.OriginBlockchainID) != destinationBlockchainID {
+			return nil
+		}
+		for _, receipt := range e.Message.Receipts {
+			if receipt.ReceivedMessageID.Cmp(messageID) == 0 {
+				receiptLog = &log
+				rewardAddress = receipt.RelayerRewardAddress
+			}
+		}
+		return nil
+	})
+	if err != nil || receiptLog == nil {
+		return trace, err
+	}
+	trace.RelayerRewardAddress = rewardAddress.Hex()
+	receiptTime, err := addStep(traceStepReceiptReceived, source, sourceBlockchainID, *receiptLog)
+	if err != nil {
+		return trace, err
+	}
+
+	// Rewards are only credited for messages sent with a fee, and are redeemed per fee token
+	// together with the rewards of the relayer's other messages.
+	feeTokenAddress := sendEvent.FeeInfo.FeeTokenAddress
+	if feeTokenAddress == (common.Address{}) {
+		return trace, nil
+	}
+	var redeemLog *types.Log
+	err = filterLogsSince(ctx, source, receiptTime, chunkSize, interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress},
+		Topics: [][]common.Hash{
+			{teleporterEventID(teleportermessenger.RelayerRewardsRedeemed)},
+			{common.BytesToHash(rewardAddress.Bytes())},
+			{common.BytesToHash(feeTokenAddress.Bytes())},
+		},
+	}, func(log types.Log) error {
+		// Redemptions in the block of the receipt may precede it.
+		if redeemLog != nil || (log.BlockNumber == receiptLog.BlockNumber && log.Index < receiptLog.Index) {
+			return nil
+		}
+		_, event, err := parseTeleporterLog(log.Topics, log.Data)
+		if err != nil {
+			return nil
+		}
+		e, ok := event.(*teleportermessenger.TeleporterMessengerRelayerRewardsRedeemed)
+		if ok && e.Redeemer == rewardAddress && e.Asset == feeTokenAddress {
+			redeemLog = &log
+		}
+		return nil
+	})
+	if err != nil || redeemLog == nil {
+		return trace, err
+	}
+	_, err = addStep(traceStepRewardRedeemed, source, sourceBlockchainID, *redeemLog)
+	return trace, err
+}
+
+// filterLogsSince calls handler on every log matching query from the first block with a
+// timestamp at or after since to the latest block
+func filterLogsSince(
+	ctx context.Context,
+	chain traceChain,
+	since uint64,
+	chunkSize uint64,
+	query interfaces.FilterQuery,
+	handler func(log types.Log) error,
+) error {
+	fromBlock, err := getBlockAtTime(ctx, chain, time.Unix(int64(since), 0))
+	if err != nil {
+		return err
+	}
+	toBlock, err := chain.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	return filterLogsInChunks(ctx, chain, query, fromBlock, toBlock, chunkSize, handler)
+}
+
+// setTraceDurations orders the steps by block time, and sets the duration of each step since
+// the previous step and since the message was sent
+func setTraceDurations(steps []traceStepOutput) {
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].timestamp < steps[j].timestamp })
+	for i := 1; i < len(steps); i++ {
+		steps[i].SincePrevious = blockTimeDuration(steps[i-1].timestamp, steps[i].timestamp)
+		steps[i].SinceSent = blockTimeDuration(steps[0].timestamp, steps[i].timestamp)
+	}
+}
+
+func blockTimeDuration(from uint64, to uint64) string {
+	return (time.Duration(to-from) * time.Second).String()
+}
+
+func teleporterEventID(event teleportermessenger.Event) common.Hash {
+	return teleporterABI.Events[event.String()].ID
+}
+
+func init() {
+	rootCmd.AddCommand(traceCmd)
+	traceCmd.PersistentFlags().StringVar(&sourceRPCEndpoint, "source-rpc", "", "RPC endpoint of the source chain")
+	traceCmd.PersistentFlags().StringVar(&destRPCEndpoint, "dest-rpc", "", "RPC endpoint of the destination chain")
+	address := traceCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addFromToFlags(traceCmd)
+	traceCmd.Flags().Uint64Var(&traceChunkSize, "chunk-size", defaultLogChunkSize,
+		"Maximum number of blocks to query logs for in a single request")
+	err := traceCmd.MarkPersistentFlagRequired("source-rpc")
+	cobra.CheckErr(err)
+	err = traceCmd.MarkPersistentFlagRequired("dest-rpc")
+	cobra.CheckErr(err)
+	err = traceCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	traceCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return sourceDestPreRunE(cmd, args, address)
+	}
+}
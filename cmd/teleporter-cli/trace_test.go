@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/subnet-evm/core/types"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"trace"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"trace", "--help"},
+			err:  nil,
+			out:  "Given the hash of the source transaction that sent a Teleporter message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+// staticChain is a chain with fixed logs, and blocks with the given timestamps
+type staticChain struct {
+	staticFilterer
+	times []uint64
+}
+
+func (c *staticChain) BlockNumber(_ context.Context) (uint64, error) {
+	return uint64(len(c.times) - 1), nil
+}
+
+func (c *staticChain) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: number, Time: c.times[number.Uint64()]}, nil
+}
+
+func newStaticChain(start uint64, blockTime uint64, blocks int, logs ...types.Log) *staticChain {
+	times := make([]uint64, blocks)
+	for i := range times {
+		times[i] = start + uint64(i)*blockTime
+	}
+	return &staticChain{staticFilterer: staticFilterer{logs: logs}, times: times}
+}
+
+func TestTraceMessage(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	chainA := ids.ID{1}
+	chainB := ids.ID{2}
+	chainC := ids.ID{3}
+	relayer := common.HexToAddress("0xd0")
+	rewardAddress := common.HexToAddress("0xa0")
+	feeToken := common.HexToAddress("0x01")
+	feeInfo := teleportermessenger.TeleporterFeeInfo{FeeTokenAddress: feeToken, Amount: big.NewInt(10)}
+	type receiptType = teleportermessenger.TeleporterMessageReceipt
+	newMessage := func(messageID int64, receipts ...receiptType) teleportermessenger.TeleporterMessage {
+		return teleportermessenger.TeleporterMessage{
+			MessageID:               big.NewInt(messageID),
+			RequiredGasLimit:        big.NewInt(0),
+			AllowedRelayerAddresses: []common.Address{},
+			Receipts:                append([]receiptType{}, receipts...),
+			Message:                 []byte{},
+		}
+	}
+	receipt := func(messageID int64) teleportermessenger.TeleporterMessageReceipt {
+		return teleportermessenger.TeleporterMessageReceipt{
+			ReceivedMessageID:    big.NewInt(messageID),
+			RelayerRewardAddress: rewardAddress,
+		}
+	}
+	at := func(log types.Log, block uint64, tx byte) types.Log {
+		log.BlockNumber = block
+		log.TxHash = common.Hash{tx}
+		return log
+	}
+	messageID := common.BigToHash(big.NewInt(1))
+	receive := func(origin ids.ID, message teleportermessenger.TeleporterMessage) types.Log {
+		return packTeleporterLog(t, "ReceiveCrossChainMessage",
+			[]common.Hash{common.Hash(origin), common.BigToHash(message.MessageID), common.BytesToHash(relayer.Bytes())},
+			rewardAddress, message)
+	}
+	redeem := func(asset common.Address) types.Log {
+		return packTeleporterLog(t, "RelayerRewardsRedeemed",
+			[]common.Hash{common.BytesToHash(rewardAddress.Bytes()), common.BytesToHash(asset.Bytes())},
+			big.NewInt(10))
+	}
+
+	sendLog := at(packTeleporterLog(t, "SendCrossChainMessage",
+		[]common.Hash{common.Hash(chainB), messageID}, newMessage(1), feeInfo), 2, 0x01)
+	sendEvent := &teleportermessenger.TeleporterMessengerSendCrossChainMessage{
+		DestinationBlockchainID: chainB,
+		MessageID:               big.NewInt(1),
+		FeeInfo:                 feeInfo,
+		Raw:                     sendLog,
+	}
+
+	// Block times of chain A are 100, 102, 104, ..., and of chain B 101, 103, 105, ...
+	source := newStaticChain(100, 2, 20,
+		// Redeemed before the receipt was received
+		at(redeem(feeToken), 1, 0x10),
+		sendLog,
+		// Receipts of other messages, and of the same message ID from another chain
+		at(receive(chainB, newMessage(5, receipt(2))), 3, 0x11),
+		at(receive(chainC, newMessage(6, receipt(1))), 4, 0x12),
+		at(receive(chainB, newMessage(7, receipt(2), receipt(1))), 8, 0x04),
+		// Redeemed for another fee token
+		at(redeem(common.HexToAddress("0x02")), 10, 0x13),
+		at(redeem(feeToken), 12, 0x05),
+		at(redeem(feeToken), 14, 0x14),
+	)
+	destination := newStaticChain(101, 2, 20,
+		at(receive(chainA, newMessage(1)), 3, 0x02),
+		at(packTeleporterLog(t, "MessageExecutionFailed",
+			[]common.Hash{common.Hash(chainA), messageID}, newMessage(1)), 3, 0x02),
+		at(packTeleporterLog(t, "MessageExecuted", []common.Hash{common.Hash(chainA), messageID}), 6, 0x03),
+	)
+
+	trace, err := traceMessage(context.Background(), source, destination, chainA, sendEvent, 4)
+	require.NoError(t, err)
+	require.Equal(t, "1", trace.MessageID)
+	require.Equal(t, relayer.Hex(), trace.Deliverer)
+	require.Equal(t, rewardAddress.Hex(), trace.RelayerRewardAddress)
+	require.Equal(t, executionSucceeded, trace.Execution)
+
+	type step struct {
+		step          string
+		blockchainID  ids.ID
+		blockNumber   uint64
+		blockTime     string
+		txHash        common.Hash
+		sincePrevious string
+		sinceSent     string
+	}
+	expected := []step{
+		{traceStepSent, chainA, 2, "1970-01-01T00:01:44Z", common.Hash{0x01}, "", ""},
+		{traceStepDelivered, chainB, 3, "1970-01-01T00:01:47Z", common.Hash{0x02}, "3s", "3s"},
+		{traceStepExecuted, chainB, 6, "1970-01-01T00:01:53Z", common.Hash{0x03}, "6s", "9s"},
+		{traceStepReceiptReceived, chainA, 8, "1970-01-01T00:01:56Z", common.Hash{0x04}, "3s", "12s"},
+		{traceStepRewardRedeemed, chainA, 12, "1970-01-01T00:02:04Z", common.Hash{0x05}, "8s", "20s"},
+	}
+	require.Len(t, trace.Steps, len(expected))
+	for i, s := range trace.Steps {
+		require.Equal(t, expected[i], step{
+			s.Step, ids.ID(common.HexToHash(s.BlockchainID.Hex)), s.BlockNumber, s.BlockTime,
+			common.HexToHash(s.TxHash), s.SincePrevious, s.SinceSent,
+		})
+	}
+
+	// A message that was not delivered yet only has the sent step
+	trace, err = traceMessage(context.Background(), source, newStaticChain(101, 2, 20), chainA, sendEvent, 4)
+	require.NoError(t, err)
+	require.Equal(t, executionPending, trace.Execution)
+	require.Len(t, trace.Steps, 1)
+	require.Equal(t, traceStepSent, trace.Steps[0].Step)
+}
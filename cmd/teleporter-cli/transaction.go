@@ -5,90 +5,245 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
 
-	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/ethclient"
 	"github.com/ava-labs/subnet-evm/x/warp"
-	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
-const (
-	warpPrecompileAddress = "0x0200000000000000000000000000000000000005"
-)
-
 var (
 	rpcEndpoint       string
 	teleporterAddress common.Address
 	client            ethclient.Client
+	transactionBlocks string
 )
 
+// blockReader reads the blocks of a chain
+type blockReader interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
 var transactionCmd = &cobra.Command{
-	Use:   "transaction --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS TRANSACTION_HASH",
-	Short: "Parses relevant Teleporter logs from a transaction",
+	Use: "transaction --rpc RPC_URL [--teleporter-address CONTRACT_ADDRESS] " +
+		"(TRANSACTION_HASH... | - | --block BLOCK[-BLOCK])",
+	Short: "Parses relevant Teleporter logs from transactions",
 	Long: `Given a transaction this command looks through the transaction's receipt
 for Teleporter and Warp log events. When corresponding log events are found,
-the command parses to log event fields to a more human readable format.`,
-	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		receipt, err := client.TransactionReceipt(context.Background(),
-			common.HexToHash(args[0]))
-		cobra.CheckErr(err)
+the command parses to log event fields to a more human readable format.
+
+Several transaction hashes may be given, or - to read whitespace separated
+hashes from stdin, or --block to parse every transaction of a block or of an
+inclusive block range such as 100-120. A single transaction hash prints a
+single result, and anything else a list of results. Logs that can not be
+decoded, such as Warp messages that do not carry a Teleporter message, are
+reported as warnings of their transaction instead of failing the command.
+Without --teleporter-address, Teleporter logs are identified by the event IDs
+of the Teleporter ABI.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if transactionBlocks != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: transactionRun,
+}
 
-		for _, log := range receipt.Logs {
-			if log.Address == teleporterAddress {
-				logger.Info("Processing Teleporter log", zap.Any("log", log))
+func transactionRun(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
 
-				event, err := teleporterABI.EventByID(log.Topics[0])
+	var txHashes []common.Hash
+	if transactionBlocks != "" {
+		fromBlock, toBlock, err := parseBlockRangeArg(transactionBlocks)
+		cobra.CheckErr(err)
+		txHashes, err = getBlockTransactions(ctx, client, fromBlock, toBlock)
+		cobra.CheckErr(err)
+	} else {
+		input := args
+		if len(args) == 1 && args[0] == "-" {
+			b, err := readInputFile(cmd, args[0])
+			cobra.CheckErr(err)
+			input = strings.Fields(string(b))
+		}
+		var err error
+		txHashes, err = parseTransactionHashes(input)
+		cobra.CheckErr(err)
+	}
+	// A single hash argument keeps the output of a single result.
+	single := len(args) == 1 && args[0] != "-"
+
+	outs := make([]transactionOutput, 0, len(txHashes))
+	for _, txHash := range txHashes {
+		receipt, err := client.TransactionReceipt(ctx, txHash)
+		if err != nil {
+			if single {
 				cobra.CheckErr(err)
+			}
+			outs = append(outs, transactionOutput{
+				TxHash:       txHash.Hex(),
+				Events:       []eventOutput{},
+				WarpMessages: []warpMessageOutput{},
+				Warnings:     []string{fmt.Sprintf("failed to get transaction receipt: %v", err)},
+			})
+			continue
+		}
+		outs = append(outs, decodeReceipt(receipt))
+	}
+	if single {
+		writeOutput(cmd, outs[0])
+	} else {
+		writeOutput(cmd, outs)
+	}
+	cmd.Println("Transaction command ran successfully")
+}
 
-				out, err := teleportermessenger.FilterTeleporterEvents(log.Topics, log.Data, event.Name)
-				cobra.CheckErr(err)
-				logger.Info("Parsed Teleporter event", zap.String("name", event.Name), zap.Any("event", out))
+// decodeReceipt decodes the Teleporter and Warp logs of a transaction receipt. Logs that fail to
+// decode are reported as warnings.
+func decodeReceipt(receipt *types.Receipt) transactionOutput {
+	out := transactionOutput{
+		TxHash:       receipt.TxHash.Hex(),
+		Status:       receipt.Status,
+		Events:       []eventOutput{},
+		WarpMessages: []warpMessageOutput{},
+	}
+	if receipt.BlockNumber != nil {
+		out.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	for _, log := range receipt.Logs {
+		switch {
+		case log.Address == warp.ContractAddress:
+			logger.Debug("Processing Warp log", zap.Any("log", log))
+
+			if teleporterAddress != (common.Address{}) && !isTeleporterWarpLog(log) {
+				out.Warnings = append(out.Warnings,
+					fmt.Sprintf("skipped Warp log %d, which was not sent by the Teleporter contract", log.Index))
+				continue
+			}
+			unsignedMsg, teleporterMessage, err := parseTeleporterWarpLog(log)
+			if err != nil {
+				out.Warnings = append(out.Warnings,
+					fmt.Sprintf("failed to decode Warp log %d as a Teleporter message: %v", log.Index, err))
+				continue
+			}
+			out.WarpMessages = append(out.WarpMessages, newWarpMessageOutput(unsignedMsg, teleporterMessage))
+		case isTeleporterLog(log):
+			logger.Debug("Processing Teleporter log", zap.Any("log", log))
+
+			name, event, err := parseTeleporterLog(log.Topics, log.Data)
+			if err != nil {
+				out.Warnings = append(out.Warnings,
+					fmt.Sprintf("failed to decode Teleporter log %d: %v", log.Index, err))
+				continue
 			}
+			out.Events = append(out.Events, newEventOutput(name, event, log))
+		}
+	}
+	return out
+}
 
-			if log.Address == common.HexToAddress(warpPrecompileAddress) {
-				logger.Debug("Processing Warp log", zap.Any("log", log))
+// isTeleporterLog returns whether log was emitted by the Teleporter contract. If the contract
+// address is not set, any log with the event ID of a Teleporter event is a Teleporter log.
+func isTeleporterLog(log *types.Log) bool {
+	if teleporterAddress != (common.Address{}) {
+		return log.Address == teleporterAddress
+	}
+	if len(log.Topics) == 0 {
+		return false
+	}
+	_, err := teleporterABI.EventByID(log.Topics[0])
+	return err == nil
+}
 
-				unsignedMsg, err := warp.UnpackSendWarpEventDataToMessage(log.Data)
-				cobra.CheckErr(err)
+// parseTransactionHashes parses hex encoded transaction hashes, with or without the 0x prefix
+func parseTransactionHashes(args []string) ([]common.Hash, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no transaction hashes given")
+	}
+	txHashes := make([]common.Hash, 0, len(args))
+	for _, arg := range args {
+		b, err := hexutil.Decode(ensureHexPrefix(arg))
+		if err != nil || len(b) != common.HashLength {
+			return nil, fmt.Errorf("invalid transaction hash %s", arg)
+		}
+		txHashes = append(txHashes, common.BytesToHash(b))
+	}
+	return txHashes, nil
+}
 
-				warpPayload, err := warpPayload.ParseAddressedCall(unsignedMsg.Payload)
-				cobra.CheckErr(err)
+// parseBlockRangeArg parses a block number, or an inclusive range of blocks such as 100-120
+func parseBlockRangeArg(arg string) (uint64, uint64, error) {
+	fromStr, toStr, isRange := strings.Cut(arg, "-")
+	fromBlock, err := strconv.ParseUint(fromStr, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid block %s", fromStr)
+	}
+	if !isRange {
+		return fromBlock, fromBlock, nil
+	}
+	toBlock, err := strconv.ParseUint(toStr, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid block %s", toStr)
+	}
+	if fromBlock > toBlock {
+		return 0, 0, fmt.Errorf("from block %d is after to block %d", fromBlock, toBlock)
+	}
+	return fromBlock, toBlock, nil
+}
 
-				teleporterMessage, err := teleportermessenger.UnpackTeleporterMessage(warpPayload.Payload)
-				cobra.CheckErr(err)
-				logger.Info("Parsed Teleporter message",
-					zap.String("warpMessageID", unsignedMsg.ID().Hex()),
-					zap.String("teleporterMessageID", teleporterMessage.MessageID.String()),
-					zap.Any("message", teleporterMessage))
-			}
+// getBlockTransactions returns the hashes of the transactions between fromBlock and toBlock, inclusive
+func getBlockTransactions(
+	ctx context.Context,
+	client blockReader,
+	fromBlock uint64,
+	toBlock uint64,
+) ([]common.Hash, error) {
+	var txHashes []common.Hash
+	for number := fromBlock; number <= toBlock; number++ {
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block %d: %w", number, err)
 		}
-		cmd.Println("Transaction command ran successfully")
-	},
+		for _, tx := range block.Transactions() {
+			txHashes = append(txHashes, tx.Hash())
+		}
+	}
+	return txHashes, nil
 }
 
 func init() {
 	rootCmd.AddCommand(transactionCmd)
 	transactionCmd.PersistentFlags().StringVar(&rpcEndpoint, "rpc", "", "RPC endpoint to connect to the node")
 	address := transactionCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addChainFlag(transactionCmd)
+	transactionCmd.Flags().StringVar(&transactionBlocks, "block", "",
+		"Block number, or inclusive range of blocks such as 100-120, to parse every transaction of")
 	err := transactionCmd.MarkPersistentFlagRequired("rpc")
 	cobra.CheckErr(err)
-	err = transactionCmd.MarkPersistentFlagRequired("teleporter-address")
-	cobra.CheckErr(err)
 	transactionCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		return transactionPreRunE(cmd, args, address)
+		return rpcPreRunE(cmd, args, address)
 	}
 }
 
-func transactionPreRunE(cmd *cobra.Command, args []string, address *string) error {
+func rpcPreRunE(cmd *cobra.Command, args []string, address *string) error {
 	// Run the persistent pre-run function of the root command if it exists.
 	if err := callPersistentPreRunE(cmd, args); err != nil {
 		return err
 	}
-	teleporterAddress = common.HexToAddress(*address)
+	if err := applyChainProfile(cmd); err != nil {
+		return err
+	}
+	// Commands that do not interact with the Teleporter contract have no address flag.
+	if address != nil {
+		teleporterAddress = common.HexToAddress(*address)
+	}
 	c, err := ethclient.Dial(rpcEndpoint)
 	if err != nil {
 		return err
@@ -2,8 +2,18 @@ package main
 
 import (
 	"fmt"
+	"math/big"
+	"strings"
 	"testing"
 
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/x/warp"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,7 +27,12 @@ func TestTransactionCmd(t *testing.T) {
 		{
 			name: "no args",
 			args: []string{"transaction"},
-			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+			err:  fmt.Errorf("requires at least 1 arg(s), only received 0"),
+		},
+		{
+			name: "block and args",
+			args: []string{"transaction", "--block", "1", "0x01"},
+			err:  fmt.Errorf("unknown command \"0x01\" for \"teleporter-cli transaction\""),
 		},
 		{
 			name: "help",
@@ -38,4 +53,173 @@ func TestTransactionCmd(t *testing.T) {
 			}
 		})
 	}
+	transactionBlocks = ""
+}
+
+func TestParseTransactionHashes(t *testing.T) {
+	hash := common.HexToHash("0x01")
+	var tests = []struct {
+		name     string
+		args     []string
+		txHashes []common.Hash
+		err      bool
+	}{
+		{
+			name:     "hashes",
+			args:     []string{hash.Hex(), common.HexToHash("0x02").Hex()},
+			txHashes: []common.Hash{hash, common.HexToHash("0x02")},
+		},
+		{
+			name: "short hash",
+			args: []string{"0x01"},
+			err:  true,
+		},
+		{
+			name:     "no prefix",
+			args:     []string{hash.Hex()[2:], common.HexToHash("0x02").Hex()},
+			txHashes: []common.Hash{hash, common.HexToHash("0x02")},
+		},
+		{
+			name: "not hex",
+			args: []string{"0x" + strings.Repeat("zz", common.HashLength)},
+			err:  true,
+		},
+		{
+			name: "empty",
+			args: []string{},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txHashes, err := parseTransactionHashes(tt.args)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.txHashes, txHashes)
+		})
+	}
+}
+
+func TestParseBlockRangeArg(t *testing.T) {
+	var tests = []struct {
+		arg       string
+		fromBlock uint64
+		toBlock   uint64
+		err       bool
+	}{
+		{arg: "100", fromBlock: 100, toBlock: 100},
+		{arg: "100-120", fromBlock: 100, toBlock: 120},
+		{arg: "120-100", err: true},
+		{arg: "100-", err: true},
+		{arg: "latest", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			fromBlock, toBlock, err := parseBlockRangeArg(tt.arg)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.fromBlock, fromBlock)
+			require.Equal(t, tt.toBlock, toBlock)
+		})
+	}
+}
+
+func TestDecodeReceipt(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	defer func() { teleporterAddress = common.Address{} }()
+
+	messenger := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	other := common.HexToAddress("0x01")
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		DestinationBlockchainID: ids.ID{2},
+		RequiredGasLimit:        big.NewInt(0),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{},
+	}
+
+	atIndex := func(log types.Log, address common.Address, index uint) *types.Log {
+		log.Address = address
+		log.Index = index
+		return &log
+	}
+	executed := packTeleporterLog(t, "MessageExecuted",
+		[]common.Hash{common.Hash(ids.ID{1}), common.BigToHash(big.NewInt(1))})
+	warpLog := func(sender common.Address, payload []byte) types.Log {
+		addressedCall, err := warpPayload.NewAddressedCall(sender.Bytes(), payload)
+		require.NoError(t, err)
+		unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{1}, addressedCall.Bytes())
+		require.NoError(t, err)
+		topics, data, err := warp.PackSendWarpMessageEvent(sender, common.Hash(unsignedMsg.ID()), unsignedMsg.Bytes())
+		require.NoError(t, err)
+		return types.Log{Topics: topics, Data: data}
+	}
+	messageBytes, err := teleportermessenger.PackTeleporterMessage(message)
+	require.NoError(t, err)
+
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		BlockNumber: big.NewInt(1),
+		Logs: []*types.Log{
+			atIndex(executed, messenger, 0),
+			atIndex(warpLog(messenger, messageBytes), warp.ContractAddress, 1),
+			// A Warp message of another application
+			atIndex(warpLog(other, []byte{1, 2, 3}), warp.ContractAddress, 2),
+			// An unknown event of the Teleporter contract
+			atIndex(types.Log{Topics: []common.Hash{{1}}}, messenger, 3),
+			// A Teleporter event emitted by another contract
+			atIndex(executed, other, 4),
+		},
+	}
+
+	var tests = []struct {
+		name              string
+		teleporterAddress common.Address
+		events            []uint
+		warpMessages      int
+		warnings          int
+	}{
+		{
+			name:              "teleporter address",
+			teleporterAddress: messenger,
+			events:            []uint{0},
+			warpMessages:      1,
+			// The other Warp message and the unknown event
+			warnings: 2,
+		},
+		{
+			name:         "event IDs",
+			events:       []uint{0, 4},
+			warpMessages: 1,
+			// The other Warp message
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teleporterAddress = tt.teleporterAddress
+			out := decodeReceipt(receipt)
+			require.Equal(t, uint64(1), out.BlockNumber)
+			var events []uint
+			for _, event := range out.Events {
+				events = append(events, *event.LogIndex)
+			}
+			require.Equal(t, tt.events, events)
+			require.Len(t, out.WarpMessages, tt.warpMessages)
+			require.Len(t, out.Warnings, tt.warnings)
+		})
+	}
 }
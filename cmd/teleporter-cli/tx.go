@@ -0,0 +1,212 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	exampleerc20 "github.com/ava-labs/teleporter/abi-bindings/go/Mocks/ExampleERC20"
+	gasUtils "github.com/ava-labs/teleporter/utils/gas-utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+const privateKeyHexLength = 64
+
+// erc20AllowanceReader is the subset of the ERC20 bindings used to check an allowance
+type erc20AllowanceReader interface {
+	Allowance(opts *bind.CallOpts, owner common.Address, spender common.Address) (*big.Int, error)
+}
+
+var (
+	privateKeyHex string
+
+	errInvalidPrivateKeyString = errors.New("invalid private key string")
+)
+
+// parsePrivateKey parses a hex encoded private key, with or without the 0x prefix
+func parsePrivateKey(keyStr string) (*ecdsa.PrivateKey, error) {
+	keyStr = strings.TrimPrefix(keyStr, "0x")
+	if len(keyStr) != privateKeyHexLength {
+		return nil, errInvalidPrivateKeyString
+	}
+	return crypto.HexToECDSA(keyStr)
+}
+
+// calculateTxParams returns the gasFeeCap, gasTipCap, and nonce to be used when
+// constructing a transaction from address
+func calculateTxParams(
+	ctx context.Context,
+	client ethclient.Client,
+	address common.Address,
+) (*big.Int, *big.Int, uint64, error) {
+	baseFee, err := client.EstimateBaseFee(ctx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	gasTipCap, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	nonce, err := client.NonceAt(ctx, address, nil)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	gasFeeCap := baseFee.Mul(baseFee, big.NewInt(gasUtils.BaseFeeFactor))
+	gasFeeCap.Add(gasFeeCap, big.NewInt(gasUtils.MaxPriorityFeePerGas))
+	return gasFeeCap, gasTipCap, nonce, nil
+}
+
+// sendContractTransaction constructs a transaction calling the contract at {to} with the given
+// call data, estimates its gas, then signs and sends it with signer.
+// Returns the receipt once the transaction is accepted.
+func sendContractTransaction(
+	ctx context.Context,
+	client ethclient.Client,
+	signer txSigner,
+	to common.Address,
+	data []byte,
+) (*types.Receipt, error) {
+	return sendTransaction(ctx, client, signer, to, common.Big0, data)
+}
+
+// sendTransaction constructs a transaction sending value to {to} with the given call data,
+// estimates its gas, then signs and sends it with signer.
+// Returns the receipt once the transaction is accepted.
+func sendTransaction(
+	ctx context.Context,
+	client ethclient.Client,
+	signer txSigner,
+	to common.Address,
+	value *big.Int,
+	data []byte,
+) (*types.Receipt, error) {
+	from := signer.Address()
+	gasLimit, err := client.EstimateGas(ctx, interfaces.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasFeeCap, gasTipCap, nonce, err := calculateTxParams(ctx, client, from)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		To:        &to,
+		Gas:       gasLimit,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		Value:     value,
+		Data:      data,
+	})
+	return signAndSendTransaction(ctx, client, tx, signer, chainID)
+}
+
+// signAndSendTransaction signs tx with signer for the specified chainID, sends it, and waits for it
+// to be accepted. Returns an error if the transaction reverted.
+func signAndSendTransaction(
+	ctx context.Context,
+	client ethclient.Client,
+	tx *types.Transaction,
+	signer txSigner,
+	chainID *big.Int,
+) (*types.Receipt, error) {
+	signedTx, err := signer.SignTx(tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		return nil, err
+	}
+	logger.Info("Sent transaction, waiting for acceptance", zap.String("txHash", signedTx.Hash().Hex()))
+
+	receipt, err := bind.WaitMined(ctx, client, signedTx)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("transaction %s failed", signedTx.Hash().Hex())
+	}
+	return receipt, nil
+}
+
+// approveERC20 approves spender to transfer amount of the ERC20 token on behalf of the signer's address,
+// unless amount is zero or the current allowance is already sufficient.
+func approveERC20(
+	ctx context.Context,
+	client ethclient.Client,
+	signer txSigner,
+	tokenAddress common.Address,
+	spender common.Address,
+	amount *big.Int,
+) error {
+	token, err := exampleerc20.NewExampleERC20(tokenAddress, client)
+	if err != nil {
+		return err
+	}
+	data, err := packERC20Approval(ctx, token, signer.Address(), spender, amount)
+	if err != nil || data == nil {
+		return err
+	}
+	receipt, err := sendContractTransaction(ctx, client, signer, tokenAddress, data)
+	if err != nil {
+		return fmt.Errorf("failed to approve ERC20: %w", err)
+	}
+	logger.Info("Approved ERC20",
+		zap.String("token", tokenAddress.Hex()),
+		zap.String("spender", spender.Hex()),
+		zap.String("amount", amount.String()),
+		zap.String("txHash", receipt.TxHash.Hex()))
+	return nil
+}
+
+// packERC20Approval returns the input of the ERC20 approve call that lets spender transfer amount
+// of token from owner, or nil if amount is zero or the allowance of owner already covers it.
+func packERC20Approval(
+	ctx context.Context,
+	token erc20AllowanceReader,
+	owner common.Address,
+	spender common.Address,
+	amount *big.Int,
+) ([]byte, error) {
+	if amount.Sign() <= 0 {
+		return nil, nil
+	}
+	allowance, err := token.Allowance(&bind.CallOpts{Context: ctx}, owner, spender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ERC20 allowance: %w", err)
+	}
+	if allowance.Cmp(amount) >= 0 {
+		logger.Debug("ERC20 allowance is sufficient", zap.String("allowance", allowance.String()))
+		return nil, nil
+	}
+
+	erc20ABI, err := exampleerc20.ExampleERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return erc20ABI.Pack("approve", spender, amount)
+}
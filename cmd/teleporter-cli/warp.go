@@ -0,0 +1,334 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/subnet-evm/params"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	warpValidatorsFile    string
+	warpQuorumNumerator   uint64
+	warpQuorumDenominator uint64
+	warpExpectedNetworkID uint32
+
+	errUnsupportedWarpSignature = errors.New("unsupported Warp signature type")
+)
+
+// validatorInput is a validator read from a validator set file
+type validatorInput struct {
+	NodeID    string        `json:"nodeID"`
+	PublicKey hexutil.Bytes `json:"publicKey"`
+	Weight    uint64        `json:"weight"`
+}
+
+// staticValidatorState serves a fixed validator set for any height and subnet, so that the
+// canonical validator set is built exactly as the destination chain would build it.
+type staticValidatorState map[ids.NodeID]*validators.GetValidatorOutput
+
+func (s staticValidatorState) GetValidatorSet(
+	context.Context,
+	uint64,
+	ids.ID,
+) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	return s, nil
+}
+
+type addressedCallOutput struct {
+	SourceAddress string `json:"sourceAddress"`
+	Payload       string `json:"payload"`
+}
+
+type bitSetSignatureOutput struct {
+	Signers       string `json:"signers"`
+	SignerIndices []int  `json:"signerIndices"`
+	NumSigners    int    `json:"numSigners"`
+	Signature     string `json:"signature"`
+}
+
+type warpSignerOutput struct {
+	Index     int      `json:"index"`
+	NodeIDs   []string `json:"nodeIDs"`
+	PublicKey string   `json:"publicKey"`
+	Weight    uint64   `json:"weight"`
+}
+
+// warpVerificationOutput is the result of verifying a Warp signature against a validator set.
+// Error is set to the first check that failed, if any.
+type warpVerificationOutput struct {
+	NumValidators      int                `json:"numValidators"`
+	Signers            []warpSignerOutput `json:"signers"`
+	SignedWeight       uint64             `json:"signedWeight"`
+	TotalWeight        uint64             `json:"totalWeight"`
+	SignedStakePercent string             `json:"signedStakePercent"`
+	QuorumNumerator    uint64             `json:"quorumNumerator"`
+	QuorumDenominator  uint64             `json:"quorumDenominator"`
+	QuorumReached      bool               `json:"quorumReached"`
+	SignatureValid     bool               `json:"signatureValid"`
+	Valid              bool               `json:"valid"`
+	Error              string             `json:"error,omitempty"`
+}
+
+type signedWarpMessageOutput struct {
+	WarpMessageID      string                  `json:"warpMessageID"`
+	NetworkID          uint32                  `json:"networkID"`
+	SourceBlockchainID blockchainIDOutput      `json:"sourceBlockchainID"`
+	Payload            string                  `json:"payload"`
+	AddressedCall      *addressedCallOutput    `json:"addressedCall,omitempty"`
+	Message            *messageOutput          `json:"message,omitempty"`
+	Signature          bitSetSignatureOutput   `json:"signature"`
+	Verification       *warpVerificationOutput `json:"verification,omitempty"`
+}
+
+var warpCmd = &cobra.Command{
+	Use:   "warp SIGNED_MESSAGE_BYTES [--validators VALIDATORS_FILE]",
+	Short: "Decodes and verifies a hex encoded signed Warp message",
+	Long: `Given the hex encoded bytes of a signed Warp message, this command decodes the
+unsigned message, its AddressedCall payload, the Teleporter message carried
+by the AddressedCall, and the signer bitset and aggregate BLS signature.
+If --validators is set to a JSON or YAML file listing the source subnet's
+validators, as objects with nodeID, publicKey and weight fields, the
+aggregate signature is verified against that validator set, and the signed
+stake is reported against the quorum. Verification follows the same steps as
+the destination chain, so a failed check explains why it rejected the
+message.`,
+	Args: cobra.ExactArgs(1),
+	Run:  warpRun,
+}
+
+func warpRun(cmd *cobra.Command, args []string) {
+	b, err := hexutil.Decode(ensureHexPrefix(args[0]))
+	cobra.CheckErr(err)
+	signedMsg, err := avalancheWarp.ParseMessage(b)
+	cobra.CheckErr(err)
+	out, err := newSignedWarpMessageOutput(signedMsg)
+	cobra.CheckErr(err)
+
+	if warpValidatorsFile != "" {
+		validatorSet, err := readValidatorSet(cmd, warpValidatorsFile)
+		cobra.CheckErr(err)
+		vdrs, totalWeight, err := avalancheWarp.GetCanonicalValidatorSet(
+			context.Background(), validatorSet, 0, ids.Empty,
+		)
+		cobra.CheckErr(err)
+
+		// newSignedWarpMessageOutput only accepts bitset signatures.
+		signature := signedMsg.Signature.(*avalancheWarp.BitSetSignature)
+		verification := verifyWarpSignature(
+			&signedMsg.UnsignedMessage, signature, vdrs, totalWeight, warpQuorumNumerator, warpQuorumDenominator,
+		)
+		// The destination chain checks the network ID before the signature.
+		if cmd.Flags().Changed("network-id") && signedMsg.NetworkID != warpExpectedNetworkID {
+			verification.Valid = false
+			verification.Error = fmt.Sprintf("%s: expected %d, got %d",
+				avalancheWarp.ErrWrongNetworkID, warpExpectedNetworkID, signedMsg.NetworkID)
+		}
+		out.Verification = &verification
+	}
+
+	writeOutput(cmd, out)
+	cmd.Println("Warp command ran successfully")
+}
+
+// newSignedWarpMessageOutput decodes a signed Warp message, along with the AddressedCall and
+// Teleporter message it carries if its payload is one
+func newSignedWarpMessageOutput(signedMsg *avalancheWarp.Message) (signedWarpMessageOutput, error) {
+	signature, ok := signedMsg.Signature.(*avalancheWarp.BitSetSignature)
+	if !ok {
+		return signedWarpMessageOutput{}, fmt.Errorf("%w: %T", errUnsupportedWarpSignature, signedMsg.Signature)
+	}
+
+	warpMessageID := signedMsg.UnsignedMessage.ID()
+	out := signedWarpMessageOutput{
+		WarpMessageID:      hexutil.Encode(warpMessageID[:]),
+		NetworkID:          signedMsg.NetworkID,
+		SourceBlockchainID: newBlockchainIDOutput(signedMsg.SourceChainID),
+		Payload:            hexutil.Encode(signedMsg.Payload),
+		Signature:          newBitSetSignatureOutput(signature),
+	}
+	if addressedCall, err := warpPayload.ParseAddressedCall(signedMsg.Payload); err == nil {
+		out.AddressedCall = &addressedCallOutput{
+			SourceAddress: hexutil.Encode(addressedCall.SourceAddress),
+			Payload:       hexutil.Encode(addressedCall.Payload),
+		}
+		if message, err := teleportermessenger.UnpackTeleporterMessage(addressedCall.Payload); err == nil {
+			messageOut := newMessageOutput(*message)
+			out.Message = &messageOut
+		}
+	}
+	return out, nil
+}
+
+func newBitSetSignatureOutput(signature *avalancheWarp.BitSetSignature) bitSetSignatureOutput {
+	signerIndices := set.BitsFromBytes(signature.Signers)
+	out := bitSetSignatureOutput{
+		Signers:       hexutil.Encode(signature.Signers),
+		SignerIndices: []int{},
+		NumSigners:    signerIndices.Len(),
+		Signature:     hexutil.Encode(signature.Signature[:]),
+	}
+	for i := 0; i < signerIndices.BitLen(); i++ {
+		if signerIndices.Contains(i) {
+			out.SignerIndices = append(out.SignerIndices, i)
+		}
+	}
+	return out
+}
+
+// readValidatorSet reads a JSON or YAML list of validators
+func readValidatorSet(cmd *cobra.Command, path string) (staticValidatorState, error) {
+	b, err := readInputFile(cmd, path)
+	if err != nil {
+		return nil, err
+	}
+	var inputs []validatorInput
+	if err := unmarshalInput(b, &inputs); err != nil {
+		return nil, fmt.Errorf("failed to parse validator set: %w", err)
+	}
+
+	validatorSet := make(staticValidatorState, len(inputs))
+	for _, input := range inputs {
+		nodeID, err := ids.NodeIDFromString(input.NodeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node ID %s: %w", input.NodeID, err)
+		}
+		vdr := &validators.GetValidatorOutput{
+			NodeID: nodeID,
+			Weight: input.Weight,
+		}
+		// Validators without a BLS public key count towards the total weight, but can not sign.
+		if len(input.PublicKey) > 0 {
+			vdr.PublicKey, err = bls.PublicKeyFromBytes(input.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public key of %s: %w", input.NodeID, err)
+			}
+		}
+		validatorSet[nodeID] = vdr
+	}
+	return validatorSet, nil
+}
+
+// verifyWarpSignature verifies signature over unsignedMsg against the canonical validator set vdrs,
+// following the steps of BitSetSignature.Verify, but reporting the result of each step rather than
+// stopping at the first failure.
+func verifyWarpSignature(
+	unsignedMsg *avalancheWarp.UnsignedMessage,
+	signature *avalancheWarp.BitSetSignature,
+	vdrs []*avalancheWarp.Validator,
+	totalWeight uint64,
+	quorumNum uint64,
+	quorumDen uint64,
+) warpVerificationOutput {
+	out := warpVerificationOutput{
+		NumValidators:      len(vdrs),
+		Signers:            []warpSignerOutput{},
+		TotalWeight:        totalWeight,
+		SignedStakePercent: stakePercent(0, totalWeight),
+		QuorumNumerator:    quorumNum,
+		QuorumDenominator:  quorumDen,
+	}
+
+	signerIndices := set.BitsFromBytes(signature.Signers)
+	if len(signerIndices.Bytes()) != len(signature.Signers) {
+		out.Error = avalancheWarp.ErrInvalidBitSet.Error()
+		return out
+	}
+	signers, err := avalancheWarp.FilterValidators(signerIndices, vdrs)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	for i, vdr := range vdrs {
+		if !signerIndices.Contains(i) {
+			continue
+		}
+		signer := warpSignerOutput{
+			Index:     i,
+			NodeIDs:   []string{},
+			PublicKey: hexutil.Encode(bls.PublicKeyToBytes(vdr.PublicKey)),
+			Weight:    vdr.Weight,
+		}
+		for _, nodeID := range vdr.NodeIDs {
+			signer.NodeIDs = append(signer.NodeIDs, nodeID.String())
+		}
+		out.Signers = append(out.Signers, signer)
+	}
+
+	// Signers are a subset of the validator set, so their weight can not overflow.
+	out.SignedWeight, _ = avalancheWarp.SumWeight(signers)
+	out.SignedStakePercent = stakePercent(out.SignedWeight, totalWeight)
+	weightErr := avalancheWarp.VerifyWeight(out.SignedWeight, totalWeight, quorumNum, quorumDen)
+	out.QuorumReached = weightErr == nil
+
+	aggSig, err := bls.SignatureFromBytes(signature.Signature[:])
+	if err != nil {
+		out.Error = fmt.Errorf("%w: %w", avalancheWarp.ErrParseSignature, err).Error()
+		return out
+	}
+	if len(signers) > 0 {
+		aggPubKey, err := avalancheWarp.AggregatePublicKeys(signers)
+		if err != nil {
+			out.Error = err.Error()
+			return out
+		}
+		out.SignatureValid = bls.Verify(aggPubKey, aggSig, unsignedMsg.Bytes())
+	}
+
+	switch {
+	case weightErr != nil:
+		out.Error = weightErr.Error()
+	case !out.SignatureValid:
+		out.Error = avalancheWarp.ErrInvalidSignature.Error()
+	default:
+		out.Valid = true
+	}
+	return out
+}
+
+// stakePercent returns weight as a percentage of totalWeight, with two decimal places
+func stakePercent(weight uint64, totalWeight uint64) string {
+	if totalWeight == 0 {
+		return "0.00"
+	}
+	percent := new(big.Rat).SetFrac(
+		new(big.Int).Mul(new(big.Int).SetUint64(weight), big.NewInt(100)),
+		new(big.Int).SetUint64(totalWeight),
+	)
+	return percent.FloatString(2)
+}
+
+// ensureHexPrefix adds the 0x prefix to a hex string if it is missing
+func ensureHexPrefix(s string) string {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return s
+	}
+	return "0x" + s
+}
+
+func init() {
+	rootCmd.AddCommand(warpCmd)
+	warpCmd.Flags().StringVar(&warpValidatorsFile, "validators", "",
+		"JSON or YAML file listing the validators of the source subnet to verify the signature against")
+	warpCmd.Flags().Uint64Var(&warpQuorumNumerator, "quorum-num", params.WarpDefaultQuorumNumerator,
+		"Quorum numerator of the signed stake")
+	warpCmd.Flags().Uint64Var(&warpQuorumDenominator, "quorum-den", params.WarpQuorumDenominator,
+		"Quorum denominator of the signed stake")
+	warpCmd.Flags().Uint32Var(&warpExpectedNetworkID, "network-id", 0,
+		"Network ID the message is expected to be sent on. Not checked if unset")
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarpCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"warp"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"warp", "--help"},
+			err:  nil,
+			out:  "Given the hex encoded bytes of a signed Warp message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestVerifyWarpSignature(t *testing.T) {
+	unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{1}, []byte{1, 2, 3})
+	require.NoError(t, err)
+
+	// Three validators with weights 10, 20 and 30, and one validator without a BLS key
+	validatorSet := make(staticValidatorState)
+	secretKeys := make(map[string]*bls.SecretKey)
+	for _, weight := range []uint64{10, 20, 30} {
+		sk, err := bls.NewSecretKey()
+		require.NoError(t, err)
+		pk := bls.PublicFromSecretKey(sk)
+		nodeID := ids.GenerateTestNodeID()
+		validatorSet[nodeID] = &validators.GetValidatorOutput{NodeID: nodeID, PublicKey: pk, Weight: weight}
+		secretKeys[string(bls.SerializePublicKey(pk))] = sk
+	}
+	nodeID := ids.GenerateTestNodeID()
+	validatorSet[nodeID] = &validators.GetValidatorOutput{NodeID: nodeID, Weight: 40}
+
+	vdrs, totalWeight, err := avalancheWarp.GetCanonicalValidatorSet(context.Background(), validatorSet, 0, ids.Empty)
+	require.NoError(t, err)
+	require.Len(t, vdrs, 3)
+	require.Equal(t, uint64(100), totalWeight)
+
+	sign := func(msg []byte, indices ...int) *avalancheWarp.BitSetSignature {
+		var sigs []*bls.Signature
+		for _, i := range indices {
+			sigs = append(sigs, bls.Sign(secretKeys[string(vdrs[i].PublicKeyBytes)], msg))
+		}
+		aggSig, err := bls.AggregateSignatures(sigs)
+		require.NoError(t, err)
+		signature := &avalancheWarp.BitSetSignature{Signers: set.NewBits(indices...).Bytes()}
+		copy(signature.Signature[:], bls.SignatureToBytes(aggSig))
+		return signature
+	}
+	allSigners := []int{0, 1, 2}
+
+	var tests = []struct {
+		name          string
+		signature     *avalancheWarp.BitSetSignature
+		quorumNum     uint64
+		signedWeight  uint64
+		percent       string
+		quorumReached bool
+		sigValid      bool
+		err           error
+	}{
+		{
+			name:          "valid",
+			signature:     sign(unsignedMsg.Bytes(), allSigners...),
+			quorumNum:     60,
+			signedWeight:  60,
+			percent:       "60.00",
+			quorumReached: true,
+			sigValid:      true,
+		},
+		{
+			name:          "insufficient weight",
+			signature:     sign(unsignedMsg.Bytes(), allSigners...),
+			quorumNum:     67,
+			signedWeight:  60,
+			percent:       "60.00",
+			quorumReached: false,
+			sigValid:      true,
+			err:           avalancheWarp.ErrInsufficientWeight,
+		},
+		{
+			name:          "wrong message",
+			signature:     sign([]byte{4, 5, 6}, allSigners...),
+			quorumNum:     60,
+			signedWeight:  60,
+			percent:       "60.00",
+			quorumReached: true,
+			sigValid:      false,
+			err:           avalancheWarp.ErrInvalidSignature,
+		},
+		{
+			name: "unknown signer",
+			signature: &avalancheWarp.BitSetSignature{
+				Signers: set.NewBits(3).Bytes(),
+			},
+			quorumNum: 60,
+			percent:   "0.00",
+			err:       avalancheWarp.ErrUnknownValidator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := verifyWarpSignature(unsignedMsg, tt.signature, vdrs, totalWeight, tt.quorumNum, 100)
+			require.Equal(t, tt.signedWeight, out.SignedWeight)
+			require.Equal(t, tt.percent, out.SignedStakePercent)
+			require.Equal(t, tt.quorumReached, out.QuorumReached)
+			require.Equal(t, tt.sigValid, out.SignatureValid)
+			if tt.err != nil {
+				require.False(t, out.Valid)
+				require.Contains(t, out.Error, tt.err.Error())
+			} else {
+				require.True(t, out.Valid)
+				require.Empty(t, out.Error)
+				require.Len(t, out.Signers, len(allSigners))
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/x/warp"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	warpEventName = "Warp"
+
+	defaultReconnectDelay = 5 * time.Second
+)
+
+var (
+	wsEndpoints           []string
+	watchEventNames       []string
+	watchOriginChain      string
+	watchDestinationChain string
+	watchMessageID        string
+	reconnectDelay        time.Duration
+	watchFilter           eventFilter
+)
+
+// eventFilter selects decoded Teleporter and Warp events by event name, route and message ID.
+// Unset fields match every event.
+type eventFilter struct {
+	events                  map[string]bool
+	originBlockchainID      ids.ID
+	destinationBlockchainID ids.ID
+	messageID               *big.Int
+}
+
+// eventRoute returns the origin and destination blockchain IDs and the message ID of a decoded
+// event emitted on the chain with the given blockchain ID. Events that do not refer to a single
+// message, such as RelayerRewardsRedeemed, return a nil message ID.
+func eventRoute(event interface{}, blockchainID ids.ID) (ids.ID, ids.ID, *big.Int) {
+	switch e := event.(type) {
+	case *teleportermessenger.TeleporterMessengerSendCrossChainMessage:
+		return blockchainID, e.DestinationBlockchainID, e.MessageID
+	case *teleportermessenger.TeleporterMessengerReceiveCrossChainMessage:
+		return e.OriginBlockchainID, blockchainID, e.MessageID
+	case *teleportermessenger.TeleporterMessengerAddFeeAmount:
+		return blockchainID, e.DestinationBlockchainID, e.MessageID
+	case *teleportermessenger.TeleporterMessengerMessageExecutionFailed:
+		return e.OriginBlockchainID, blockchainID, e.MessageID
+	case *teleportermessenger.TeleporterMessengerMessageExecuted:
+		return e.OriginBlockchainID, blockchainID, e.MessageID
+	case *teleportermessenger.TeleporterMessage:
+		return blockchainID, e.DestinationBlockchainID, e.MessageID
+	default:
+		return ids.Empty, ids.Empty, nil
+	}
+}
+
+// matches returns whether the named event emitted on the chain with the given blockchain ID
+// passes the filter
+func (f *eventFilter) matches(name string, event interface{}, blockchainID ids.ID) bool {
+	if len(f.events) > 0 && !f.events[strings.ToLower(name)] {
+		return false
+	}
+	origin, destination, messageID := eventRoute(event, blockchainID)
+	if f.originBlockchainID != ids.Empty && origin != f.originBlockchainID {
+		return false
+	}
+	if f.destinationBlockchainID != ids.Empty && destination != f.destinationBlockchainID {
+		return false
+	}
+	if f.messageID != nil && (messageID == nil || messageID.Cmp(f.messageID) != 0) {
+		return false
+	}
+	return true
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch --ws WS_URL[,WS_URL...] --teleporter-address CONTRACT_ADDRESS [flags]",
+	Short: "Streams decoded Teleporter and Warp events as they are emitted",
+	Long: `Subscribes to the Teleporter and Warp logs of one or more chains over websocket
+and prints every decoded event as it arrives: SendCrossChainMessage,
+ReceiveCrossChainMessage, MessageExecuted, MessageExecutionFailed,
+AddFeeAmount, RelayerRewardsRedeemed, and each Teleporter Warp message with
+its AddressedCall and the Teleporter message it carries. Events can be
+filtered by event name, origin and destination blockchain ID, and message ID.
+When a websocket connection drops, the command reconnects and catches up on
+the logs it missed, from the last log it handled. Events whose logs are
+removed by a reorg are printed again, marked as removed.`,
+	Args: cobra.NoArgs,
+	Run:  watchRun,
+}
+
+func watchRun(cmd *cobra.Command, args []string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var wg sync.WaitGroup
+	for _, endpoint := range wsEndpoints {
+		wg.Add(1)
+		go func(endpoint string) {
+			defer wg.Done()
+			watchChain(ctx, cmd, endpoint)
+		}(endpoint)
+	}
+	wg.Wait()
+	cmd.Println("Watch command ran successfully")
+}
+
+// watchCursor is the position just after the last log handled on a chain, so that logs
+// delivered again after a reconnect are skipped
+type watchCursor struct {
+	// started is set once the cursor is positioned after the head block of the first subscription
+	started  bool
+	block    uint64
+	logIndex uint
+}
+
+// advance moves the cursor past log, and returns false if the log was already handled
+func (c *watchCursor) advance(log types.Log) bool {
+	if log.BlockNumber < c.block || (log.BlockNumber == c.block && log.Index < c.logIndex) {
+		return false
+	}
+	c.block, c.logIndex = log.BlockNumber, log.Index+1
+	return true
+}
+
+// rewind moves the cursor back to a log removed by a reorg, so that the logs replacing it are handled
+func (c *watchCursor) rewind(log types.Log) {
+	if log.BlockNumber < c.block || (log.BlockNumber == c.block && log.Index < c.logIndex) {
+		c.block, c.logIndex = log.BlockNumber, log.Index
+	}
+}
+
+// watchChain streams the events of the chain at endpoint until ctx is cancelled,
+// reconnecting whenever the subscription fails.
+func watchChain(ctx context.Context, cmd *cobra.Command, endpoint string) {
+	cursor := &watchCursor{}
+	for ctx.Err() == nil {
+		err := subscribeChain(ctx, cmd, endpoint, cursor)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("Websocket subscription dropped, reconnecting",
+			zap.String("endpoint", endpoint),
+			zap.Duration("delay", reconnectDelay),
+			zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// subscribeChain connects to endpoint, catches up on any logs emitted after the cursor, and
+// then handles new logs until the subscription fails or ctx is cancelled.
+func subscribeChain(ctx context.Context, cmd *cobra.Command, endpoint string, cursor *watchCursor) error {
+	wsClient, err := ethclient.DialContext(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	defer wsClient.Close()
+
+	blockchainID, err := getBlockchainID(ctx, wsClient)
+	if err != nil {
+		return err
+	}
+	// Logs are streamed from the block after the head at the first subscription, including those
+	// emitted before the subscription is open or while it is dropped.
+	if !cursor.started {
+		head, err := wsClient.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		*cursor = watchCursor{started: true, block: head + 1}
+	}
+	query := interfaces.FilterQuery{
+		Addresses: []common.Address{teleporterAddress, warp.ContractAddress},
+	}
+
+	logs := make(chan types.Log)
+	sub, err := wsClient.SubscribeFilterLogs(ctx, query, logs)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+	logger.Info("Subscribed to Teleporter logs",
+		zap.String("endpoint", endpoint),
+		zap.String("blockchainID", blockchainID.String()))
+
+	handle := func(log types.Log) {
+		handleWatchedLog(cmd, log, blockchainID)
+	}
+	// The subscription is already open, so no log is missed between the catch up and the
+	// subscription. Logs delivered by both are skipped by the cursor.
+	if err := catchUpWatchedLogs(ctx, wsClient, query, cursor, handle); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-sub.Err():
+			return err
+		case log := <-logs:
+			// A reorg delivers the logs of the blocks it removed, marked as removed, before the logs
+			// of the blocks replacing them.
+			if log.Removed {
+				cursor.rewind(log)
+				handle(log)
+			} else if cursor.advance(log) {
+				handle(log)
+			}
+		}
+	}
+}
+
+// catchUpWatchedLogs handles the logs matching query from the cursor to the latest block
+func catchUpWatchedLogs(
+	ctx context.Context,
+	client latestLogReader,
+	query interfaces.FilterQuery,
+	cursor *watchCursor,
+	handle func(log types.Log),
+) error {
+	latest, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if cursor.block > latest {
+		return nil
+	}
+	return filterLogsInChunks(ctx, client, query, cursor.block, latest, defaultLogChunkSize, func(log types.Log) error {
+		if cursor.advance(log) {
+			handle(log)
+		}
+		return nil
+	})
+}
+
+// handleWatchedLog decodes a Teleporter or Warp log and prints it if it passes the filter
+func handleWatchedLog(cmd *cobra.Command, log types.Log, blockchainID ids.ID) {
+	if out, ok := decodeWatchedLog(log, blockchainID); ok {
+		writeStreamOutput(cmd, out)
+	}
+}
+
+// decodeWatchedLog decodes a Teleporter or Warp log emitted on the chain with the given blockchain
+// ID. Returns false if the log is not a Teleporter event or Warp message, or does not pass the filter.
+func decodeWatchedLog(log types.Log, blockchainID ids.ID) (eventOutput, bool) {
+	var out eventOutput
+	switch log.Address {
+	case teleporterAddress:
+		name, event, err := parseTeleporterLog(log.Topics, log.Data)
+		if err != nil {
+			logger.Warn("Failed to parse Teleporter log", zap.String("txHash", log.TxHash.Hex()), zap.Error(err))
+			return eventOutput{}, false
+		}
+		if !watchFilter.matches(name, event, blockchainID) {
+			return eventOutput{}, false
+		}
+		out = newEventOutput(name, event, &log)
+	case warp.ContractAddress:
+		if !isTeleporterWarpLog(&log) {
+			return eventOutput{}, false
+		}
+		unsignedMsg, teleporterMessage, err := parseTeleporterWarpLog(&log)
+		if err != nil {
+			logger.Warn("Failed to parse Warp log", zap.String("txHash", log.TxHash.Hex()), zap.Error(err))
+			return eventOutput{}, false
+		}
+		if !watchFilter.matches(warpEventName, teleporterMessage, blockchainID) {
+			return eventOutput{}, false
+		}
+		out = newEventOutput(warpEventName, nil, &log)
+		out.Event = newWarpMessageOutput(unsignedMsg, teleporterMessage)
+	default:
+		return eventOutput{}, false
+	}
+	id := newBlockchainIDOutput(blockchainID)
+	out.BlockchainID = &id
+	return out, true
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.PersistentFlags().StringSliceVar(&wsEndpoints, "ws", []string{},
+		"Websocket endpoints of the chains to watch")
+	address := watchCmd.PersistentFlags().StringP("teleporter-address", "t", "", "Teleporter contract address")
+	addChainsFlag(watchCmd)
+	watchCmd.Flags().StringSliceVar(&watchEventNames, "events", []string{},
+		"Names of the events to print, i.e. SendCrossChainMessage,Warp. Prints all events if empty")
+	watchCmd.Flags().StringVar(&watchOriginChain, "origin-blockchain-id", "",
+		"Only print events of messages from this blockchain ID, in cb58 or hex, or chain name")
+	watchCmd.Flags().StringVar(&watchDestinationChain, "destination-blockchain-id", "",
+		"Only print events of messages to this blockchain ID, in cb58 or hex, or chain name")
+	watchCmd.Flags().StringVar(&watchMessageID, "message-id", "", "Only print events of this message ID")
+	watchCmd.Flags().DurationVar(&reconnectDelay, "reconnect-delay", defaultReconnectDelay,
+		"Delay before reconnecting a dropped websocket connection")
+	err := watchCmd.MarkPersistentFlagRequired("ws")
+	cobra.CheckErr(err)
+	err = watchCmd.MarkPersistentFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+	watchCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return watchPreRunE(cmd, args, address)
+	}
+}
+
+func watchPreRunE(cmd *cobra.Command, args []string, address *string) error {
+	// Run the persistent pre-run function of the root command if it exists.
+	if err := callPersistentPreRunE(cmd, args); err != nil {
+		return err
+	}
+	if err := applyChainsProfile(cmd, "ws"); err != nil {
+		return err
+	}
+	teleporterAddress = common.HexToAddress(*address)
+
+	watchFilter = eventFilter{events: make(map[string]bool)}
+	for _, name := range watchEventNames {
+		if !strings.EqualFold(name, warpEventName) {
+			if _, err := teleportermessenger.ToEvent(name); err != nil {
+				return err
+			}
+		}
+		watchFilter.events[strings.ToLower(name)] = true
+	}
+	if watchOriginChain != "" {
+		id, err := resolveBlockchainID(watchOriginChain)
+		if err != nil {
+			return err
+		}
+		watchFilter.originBlockchainID = id
+	}
+	if watchDestinationChain != "" {
+		id, err := resolveBlockchainID(watchDestinationChain)
+		if err != nil {
+			return err
+		}
+		watchFilter.destinationBlockchainID = id
+	}
+	if watchMessageID != "" {
+		messageID, ok := new(big.Int).SetString(watchMessageID, 0)
+		if !ok {
+			return fmt.Errorf("invalid message ID %s", watchMessageID)
+		}
+		watchFilter.messageID = messageID
+	}
+	return nil
+}
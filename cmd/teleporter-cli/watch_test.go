@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/x/warp"
+	teleportermessenger "github.com/ava-labs/teleporter/abi-bindings/go/Teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "extra args",
+			args: []string{"watch", "extra"},
+			err:  fmt.Errorf("unknown command \"extra\" for \"teleporter-cli watch\""),
+		},
+		{
+			name: "help",
+			args: []string{"watch", "--help"},
+			err:  nil,
+			out:  "Subscribes to the Teleporter and Warp logs of one or more chains over websocket",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	chainA := ids.ID{1}
+	chainB := ids.ID{2}
+	sendEvent := &teleportermessenger.TeleporterMessengerSendCrossChainMessage{
+		DestinationBlockchainID: chainB,
+		MessageID:               big.NewInt(1),
+	}
+	redeemEvent := &teleportermessenger.TeleporterMessengerRelayerRewardsRedeemed{}
+
+	var tests = []struct {
+		name    string
+		filter  eventFilter
+		event   string
+		out     interface{}
+		matches bool
+	}{
+		{
+			name:    "empty filter",
+			filter:  eventFilter{},
+			event:   "SendCrossChainMessage",
+			out:     sendEvent,
+			matches: true,
+		},
+		{
+			name:    "event name",
+			filter:  eventFilter{events: map[string]bool{"receivecrosschainmessage": true}},
+			event:   "SendCrossChainMessage",
+			out:     sendEvent,
+			matches: false,
+		},
+		{
+			name:    "origin",
+			filter:  eventFilter{originBlockchainID: chainA},
+			event:   "SendCrossChainMessage",
+			out:     sendEvent,
+			matches: true,
+		},
+		{
+			name:    "destination",
+			filter:  eventFilter{destinationBlockchainID: chainA},
+			event:   "SendCrossChainMessage",
+			out:     sendEvent,
+			matches: false,
+		},
+		{
+			name:    "message ID",
+			filter:  eventFilter{messageID: big.NewInt(2)},
+			event:   "SendCrossChainMessage",
+			out:     sendEvent,
+			matches: false,
+		},
+		{
+			name:    "message ID without route",
+			filter:  eventFilter{messageID: big.NewInt(1)},
+			event:   "RelayerRewardsRedeemed",
+			out:     redeemEvent,
+			matches: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.matches, tt.filter.matches(tt.event, tt.out, chainA))
+		})
+	}
+}
+
+func TestCatchUpWatchedLogs(t *testing.T) {
+	at := func(block uint64, index uint) types.Log {
+		return types.Log{BlockNumber: block, Index: index}
+	}
+	chain := newStaticChain(0, 1, 6, at(1, 0), at(3, 0), at(3, 1), at(3, 2), at(5, 0))
+
+	var handled []types.Log
+	handle := func(log types.Log) {
+		handled = append(handled, log)
+	}
+
+	// The first subscription starts after the head block, and a drop before any log arrives
+	// still catches up on the logs emitted since.
+	cursor := &watchCursor{started: true, block: 2}
+	require.NoError(t, catchUpWatchedLogs(context.Background(), chain, interfaces.FilterQuery{}, cursor, handle))
+	require.Equal(t, []types.Log{at(3, 0), at(3, 1), at(3, 2), at(5, 0)}, handled)
+	require.Equal(t, watchCursor{started: true, block: 5, logIndex: 1}, *cursor)
+
+	// Resuming within a block skips the logs of the block that were already handled.
+	handled = nil
+	cursor = &watchCursor{started: true, block: 3, logIndex: 1}
+	require.NoError(t, catchUpWatchedLogs(context.Background(), chain, interfaces.FilterQuery{}, cursor, handle))
+	require.Equal(t, []types.Log{at(3, 1), at(3, 2), at(5, 0)}, handled)
+
+	// Logs delivered again by the subscription are skipped.
+	require.False(t, cursor.advance(at(5, 0)))
+	require.False(t, cursor.advance(at(3, 2)))
+	require.True(t, cursor.advance(at(5, 1)))
+	require.True(t, cursor.advance(at(6, 0)))
+
+	// Logs removed by a reorg, delivered oldest first, move the cursor back to the first of them,
+	// so that the logs replacing them are handled.
+	cursor.rewind(at(5, 0))
+	cursor.rewind(at(5, 1))
+	require.Equal(t, watchCursor{started: true, block: 5, logIndex: 0}, *cursor)
+	require.True(t, cursor.advance(at(5, 0)))
+	cursor.rewind(at(7, 0))
+	require.Equal(t, watchCursor{started: true, block: 5, logIndex: 1}, *cursor)
+	require.True(t, cursor.advance(at(6, 0)))
+
+	// Nothing to catch up on past the latest block
+	handled = nil
+	require.NoError(t, catchUpWatchedLogs(context.Background(), chain, interfaces.FilterQuery{}, cursor, handle))
+	require.Empty(t, handled)
+}
+
+func TestDecodeWatchedLog(t *testing.T) {
+	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	teleporterABI = abi
+	logger = logging.NoLog{}
+	teleporterAddress = common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	defer func() { watchFilter = eventFilter{} }()
+
+	chainA := ids.ID{1}
+	message := teleportermessenger.TeleporterMessage{
+		MessageID:               big.NewInt(1),
+		DestinationBlockchainID: ids.ID{2},
+		RequiredGasLimit:        big.NewInt(0),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{},
+	}
+	messageBytes, err := teleportermessenger.PackTeleporterMessage(message)
+	require.NoError(t, err)
+	addressedCall, err := warpPayload.NewAddressedCall(teleporterAddress.Bytes(), messageBytes)
+	require.NoError(t, err)
+	unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, chainA, addressedCall.Bytes())
+	require.NoError(t, err)
+	topics, data, err := warp.PackSendWarpMessageEvent(
+		teleporterAddress, common.Hash(unsignedMsg.ID()), unsignedMsg.Bytes())
+	require.NoError(t, err)
+	warpLog := types.Log{Address: warp.ContractAddress, Topics: topics, Data: data, BlockNumber: 3}
+
+	watchFilter = eventFilter{}
+	out, ok := decodeWatchedLog(warpLog, chainA)
+	require.True(t, ok)
+	require.Equal(t, warpEventName, out.Name)
+	require.Equal(t, newBlockchainIDOutput(chainA), *out.BlockchainID)
+	warpOut, ok := out.Event.(warpMessageOutput)
+	require.True(t, ok)
+	warpMessageID := unsignedMsg.ID()
+	require.Equal(t, hexutil.Encode(warpMessageID[:]), warpOut.WarpMessageID)
+	require.Equal(t, hexutil.Encode(teleporterAddress.Bytes()), warpOut.AddressedCall.SourceAddress)
+	require.Equal(t, hexutil.Encode(messageBytes), warpOut.AddressedCall.Payload)
+	require.Equal(t, "1", warpOut.Message.MessageID)
+	require.False(t, out.Removed)
+
+	// Logs removed by a reorg are marked as removed
+	removedLog := warpLog
+	removedLog.Removed = true
+	out, ok = decodeWatchedLog(removedLog, chainA)
+	require.True(t, ok)
+	require.True(t, out.Removed)
+
+	// The filter applies to the route of the Teleporter message
+	watchFilter = eventFilter{destinationBlockchainID: ids.ID{3}}
+	_, ok = decodeWatchedLog(warpLog, chainA)
+	require.False(t, ok)
+
+	// Logs of other contracts are skipped
+	watchFilter = eventFilter{}
+	_, ok = decodeWatchedLog(types.Log{Address: common.HexToAddress("0x01")}, chainA)
+	require.False(t, ok)
+}
@@ -85,6 +85,21 @@ func ExtractByteCode(byteCodeFileName string) ([]byte, error) {
 	return byteCode, nil
 }
 
+// KeylessTransactionFiles are the paths that a keyless transaction, its deployer address, and its
+// contract address are written to. Empty paths are not written.
+type KeylessTransactionFiles struct {
+	Transaction     string
+	DeployerAddress string
+	ContractAddress string
+}
+
+// DefaultKeylessTransactionFiles are the files written by ConstructKeylessTransaction, in the current directory
+var DefaultKeylessTransactionFiles = KeylessTransactionFiles{
+	Transaction:     contractCreationTxFileName,
+	DeployerAddress: contractCreationAddrFileName,
+	ContractAddress: universalContractAddressFileName,
+}
+
 // Constructs a keyless transaction using Nick's method
 // Optionally writes the transaction, deployer address, and contract address to file
 // Returns the transaction bytes, deployer address, and contract address
@@ -92,6 +107,37 @@ func ConstructKeylessTransaction(
 	byteCodeFileName string,
 	writeFile bool,
 ) ([]byte, common.Address, common.Address, error) {
+	byteCode, err := ExtractByteCode(byteCodeFileName)
+	if err != nil {
+		return nil, common.Address{}, common.Address{}, err
+	}
+	contractCreationTxBytes, senderAddress, contractAddress, err := ConstructKeylessTransactionFromByteCode(byteCode)
+	if err != nil {
+		return nil, common.Address{}, common.Address{}, err
+	}
+
+	log.Println("Raw Teleporter Contract Creation Transaction:")
+	log.Println("0x" + hex.EncodeToString(contractCreationTxBytes))
+	log.Println("Teleporter Contract Keyless Deployer Address: ", senderAddress.Hex())
+	log.Println("Teleporter Messenger Universal Contract Address: ", contractAddress.Hex())
+
+	if writeFile {
+		err = WriteKeylessTransactionFiles(
+			DefaultKeylessTransactionFiles,
+			contractCreationTxBytes,
+			senderAddress,
+			contractAddress,
+		)
+		if err != nil {
+			return nil, common.Address{}, common.Address{}, err
+		}
+	}
+	return contractCreationTxBytes, senderAddress, contractAddress, nil
+}
+
+// Constructs a keyless transaction using Nick's method that deploys the given contract byte code
+// Returns the transaction bytes, deployer address, and contract address
+func ConstructKeylessTransactionFromByteCode(byteCode []byte) ([]byte, common.Address, common.Address, error) {
 	// Convert the R and S values (which must be the same) from hex.
 	rsValue, ok := new(big.Int).SetString(rsValueHex, 16)
 	if !ok {
@@ -100,11 +146,6 @@ func ConstructKeylessTransaction(
 		)
 	}
 
-	byteCode, err := ExtractByteCode(byteCodeFileName)
-	if err != nil {
-		return nil, common.Address{}, common.Address{}, err
-	}
-
 	// Construct the legacy transaction with pre-determined signature values.
 	contractCreationTx := types.NewTx(&types.LegacyTx{
 		Nonce:    0,
@@ -127,7 +168,7 @@ func ConstructKeylessTransaction(
 		)
 	}
 
-	// Serialize the raw transaction and sender address.
+	// Serialize the raw transaction.
 	contractCreationTxBytes, err := contractCreationTx.MarshalBinary()
 	if err != nil {
 		return nil, common.Address{}, common.Address{}, errors.Wrap(
@@ -135,8 +176,6 @@ func ConstructKeylessTransaction(
 			"Failed to serialize raw transaction",
 		)
 	}
-	contractCreationTxString := "0x" + hex.EncodeToString(contractCreationTxBytes)
-	senderAddressString := senderAddress.Hex() // "0x" prepended by Hex() already.
 
 	// Derive the resulting contract address given that it will be deployed from the sender address using the nonce of 0.
 	contractAddress, err := DeriveEVMContractAddress(senderAddress, 0)
@@ -146,45 +185,33 @@ func ConstructKeylessTransaction(
 			"Failed to derive contract address",
 		)
 	}
-	contractAddressString := contractAddress.Hex() // "0x" prepended by Hex() already.
-
-	log.Println("Raw Teleporter Contract Creation Transaction:")
-	log.Println(contractCreationTxString)
-	log.Println("Teleporter Contract Keyless Deployer Address: ", senderAddressString)
-	log.Println("Teleporter Messenger Universal Contract Address: ", contractAddressString)
-
-	if writeFile {
-		err = os.WriteFile(
-			contractCreationTxFileName,
-			[]byte(contractCreationTxString),
-			fs.ModePerm,
-		)
-		if err != nil {
-			return nil, common.Address{}, common.Address{}, errors.Wrap(
-				err,
-				"Failed to write to contract creation tx file",
-			)
-		}
+	return contractCreationTxBytes, senderAddress, contractAddress, nil
+}
 
-		err = os.WriteFile(contractCreationAddrFileName, []byte(senderAddressString), fs.ModePerm)
-		if err != nil {
-			return nil, common.Address{}, common.Address{}, errors.Wrap(
-				err,
-				"Failed to write to deployer address file",
-			)
+// Writes the hex encoded transaction, deployer address, and contract address of a keyless transaction
+// to the given files
+func WriteKeylessTransactionFiles(
+	files KeylessTransactionFiles,
+	contractCreationTxBytes []byte,
+	senderAddress common.Address,
+	contractAddress common.Address,
+) error {
+	contents := []struct {
+		path        string
+		value       string
+		description string
+	}{
+		{files.Transaction, "0x" + hex.EncodeToString(contractCreationTxBytes), "contract creation tx file"},
+		{files.DeployerAddress, senderAddress.Hex(), "deployer address file"}, // "0x" prepended by Hex() already.
+		{files.ContractAddress, contractAddress.Hex(), "contract address file"},
+	}
+	for _, c := range contents {
+		if c.path == "" {
+			continue
 		}
-
-		err = os.WriteFile(
-			universalContractAddressFileName,
-			[]byte(contractAddressString),
-			fs.ModePerm,
-		)
-		if err != nil {
-			return nil, common.Address{}, common.Address{}, errors.Wrap(
-				err,
-				"Failed to write to contract address",
-			)
+		if err := os.WriteFile(c.path, []byte(c.value), fs.ModePerm); err != nil {
+			return errors.Wrap(err, "Failed to write to "+c.description)
 		}
 	}
-	return contractCreationTxBytes, senderAddress, contractAddress, nil
+	return nil
 }